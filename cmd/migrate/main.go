@@ -0,0 +1,155 @@
+// Command migrate はデータベースのマイグレーションを管理します。
+//
+// 使い方:
+//
+//	go run ./cmd/migrate up [n]      未適用のマイグレーションを適用（nを省略するとすべて）
+//	go run ./cmd/migrate down [n]    適用済みのマイグレーションを新しい順に取り消し（nを省略すると1つ）
+//	go run ./cmd/migrate status      適用状況を表示
+//	go run ./cmd/migrate force V     マイグレーションを実行せずにバージョンVまで適用済みとして記録
+//	go run ./cmd/migrate verify      一時的なスキーマでup/downを確認し、モデルとの差分を表示
+//
+// 接続先はサーバーと同じ設定（APP_ENVのプロファイルとSUPABASE_URLなど）から読み込みます。
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/migrate"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/migrations"
+)
+
+const usage = `usage: migrate <command> [args]
+
+commands:
+  up [n]      apply pending migrations (all if n is omitted)
+  down [n]    roll back applied migrations, newest first (1 if n is omitted)
+  status      show applied and pending migrations
+  force V     record versions up to V as applied without running them
+  verify      check up/down in a scratch schema and compare the result with the models`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(context.Background(), os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, command string, args []string) error {
+	ms, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.SlogLevel()))
+	db, err := config.OpenDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	runner := migrate.NewRunner(sqlDB, ms)
+
+	switch command {
+	case "up":
+		n, err := optionalCount(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := runner.Up(ctx, n)
+		for _, m := range applied {
+			fmt.Println("applied", m)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		n, err := optionalCount(args, 1)
+		if err != nil {
+			return err
+		}
+		reverted, err := runner.Down(ctx, n)
+		for _, m := range reverted {
+			fmt.Println("reverted", m)
+		}
+		return err
+
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\n", s.Migration, appliedAt)
+		}
+		return w.Flush()
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("force requires a version")
+		}
+		version, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if err := runner.Force(ctx, uint(version)); err != nil {
+			return err
+		}
+		fmt.Println("forced version", version)
+		return nil
+
+	case "verify":
+		diffs, err := migrate.Verify(ctx, sqlDB, ms, models.All())
+		if err != nil {
+			return err
+		}
+		for _, diff := range diffs {
+			fmt.Println(diff)
+		}
+		if len(diffs) > 0 {
+			return fmt.Errorf("%d difference(s) between migrations and models", len(diffs))
+		}
+		fmt.Println("migrations and models are in sync")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+// optionalCount は省略可能な個数の引数を解釈します。省略した場合はdefを返します。
+func optionalCount(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count %q", args[0])
+	}
+	return n, nil
+}
@@ -0,0 +1,76 @@
+// Command user はコマンドラインからユーザーを管理します。
+//
+// 使い方:
+//
+//	go run ./cmd/user set-role USERNAME ROLE    ユーザーのロール（user または admin）を変更
+//
+// 最初の管理者は管理者APIで設定できないため、このコマンドで設定します。
+// ロールを変更したユーザーのセッションはすべて失効します。
+// 接続先はサーバーと同じ設定（APP_ENVのプロファイルとSUPABASE_URLなど）から読み込みます。
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+)
+
+const usage = `usage: user <command> [args]
+
+commands:
+  set-role USERNAME ROLE    change the role of a user (user or admin) and revoke their sessions`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(context.Background(), os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "user:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, command string, args []string) error {
+	if command != "set-role" {
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("set-role requires USERNAME and ROLE\n\n%s", usage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.SlogLevel()))
+	db, err := config.OpenDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	jwtManager := token.NewJWTManager(
+		cfg.JWT.AccessSecret,
+		cfg.JWT.RefreshSecret,
+		cfg.JWT.Issuer,
+		cfg.JWT.AccessTTL,
+		cfg.JWT.RefreshTTL,
+	)
+	tokenService := service.NewTokenService(repository.NewRefreshTokenRepository(db), userRepo, jwtManager)
+	adminService := service.NewAdminService(userRepo, repository.NewChallengeRepository(db), tokenService)
+
+	username, role := args[0], args[1]
+	if err := adminService.AssignRoleByUsername(ctx, username, role); err != nil {
+		return err
+	}
+	fmt.Printf("set role of %s to %s\n", username, role)
+	return nil
+}
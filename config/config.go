@@ -1,142 +1,350 @@
+// Package config はアプリケーションの設定を読み込みます。
+//
+// 設定は既定値、YAMLファイル、環境変数の順に読み込み、後のものが優先されます。
+// YAMLファイルはCONFIG_DIR（既定: config）のbase.yamlと、APP_ENV（既定: dev）で選択したプロファイルの
+// <プロファイル>.yaml です。シークレットはYAMLに書かず環境変数で指定してください。
+// 読み込んだ設定はLoadでまとめて検証し、問題をすべて列挙したエラーを返します。
 package config
 
 import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
+	"gopkg.in/yaml.v3"
 )
 
-var DB *gorm.DB
-var err error
+// プロファイル
+const (
+	ProfileDev  = "dev"  // 開発
+	ProfileProd = "prod" // 本番（シークレットの強度などを厳しく検証）
+)
 
-func InitDB() {
-	LoadEnv()
+// Config はアプリケーションの設定です。
+type Config struct {
+	Profile           string                  `yaml:"-"`
+	Server            ServerConfig            `yaml:"server"`
+	Database          DatabaseConfig          `yaml:"database"`
+	JWT               JWTConfig               `yaml:"jwt"`
+	Session           SessionConfig           `yaml:"session"`
+	FrontendURL       string                  `yaml:"frontend_url"` // メール内のリンク生成に使用
+	OAuth             OAuthConfig             `yaml:"oauth"`
+	Mail              MailConfig              `yaml:"mail"`
+	PasswordReset     PasswordResetConfig     `yaml:"password_reset"`
+	EmailVerification EmailVerificationConfig `yaml:"email_verification"`
+	Username          UsernameConfig          `yaml:"username"`
+	TOTP              TOTPConfig              `yaml:"totp"`
+	Health            HealthConfig            `yaml:"health"`
+	Metrics           MetricsConfig           `yaml:"metrics"`
+	Log               LogConfig               `yaml:"log"`
+	Tracing           TracingConfig           `yaml:"tracing"`
+}
 
-	supabaseURL := os.Getenv("SUPABASE_URL")
+// ServerConfig はHTTPサーバーの設定です。
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"` // リクエストボディの読み込みを含む。0は無制限
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"` // 0は無制限
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"` // 停止時に処理中のリクエストの完了を待つ時間
+	TLSCertFile       string        `yaml:"tls_cert_file"`    // tls_key_fileとあわせて設定するとHTTPSで待ち受ける
+	TLSKeyFile        string        `yaml:"tls_key_file"`
+}
 
-	dsn := fmt.Sprintf("%s", supabaseURL)
+// TLSEnabled はHTTPSで待ち受けるかを返します。
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
 
-	dbConfig := postgres.New(postgres.Config{
-		DSN:                  dsn,
-		PreferSimpleProtocol: true,
-	})
-	DB, err = gorm.Open(dbConfig, &gorm.Config{})
-	log.Println("Using DSN:", dsn) // この行を追加
+// DatabaseConfig はデータベース接続の設定です。
+type DatabaseConfig struct {
+	URL             string        `yaml:"url"`
+	AutoMigrate     bool          `yaml:"auto_migrate"` // 起動時に未適用のマイグレーションを適用する
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	LogSQL          bool          `yaml:"log_sql"` // 実行したすべてのSQLをログに出力する
+}
 
-	if err != nil {
-		log.Fatal("failed to connect to database:", err)
-	}
+// JWTConfig はアクセストークン・リフレッシュトークンの設定です。
+type JWTConfig struct {
+	AccessSecret  string        `yaml:"access_secret"`
+	RefreshSecret string        `yaml:"refresh_secret"` // 未設定の場合はaccess_secretと同じ
+	Issuer        string        `yaml:"issuer"`
+	AccessTTL     time.Duration `yaml:"access_ttl"`
+	RefreshTTL    time.Duration `yaml:"refresh_ttl"`
+}
 
-	sqlDB, err := DB.DB()
-	if err != nil {
-		log.Fatal("failed to get generic database object:", err)
-	}
+// SessionConfig はOAuthログイン中に使用するCookieセッションの設定です。
+type SessionConfig struct {
+	Secret string `yaml:"secret"`
+}
 
-	// 接続プールの設定
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+// OAuthConfig はOAuthログインの設定です。
+type OAuthConfig struct {
+	GitHub OAuthProviderConfig  `yaml:"github"` // client_idを設定した場合のみ有効
+	Google OAuthProviderConfig  `yaml:"google"` // client_idを設定した場合のみ有効
+	OIDC   []OIDCProviderConfig `yaml:"oidc"`
+	// ログイン後のリダイレクト先として許可するURI（完全一致）。未設定の場合は {frontend_url}/auth/callback
+	RedirectAllowlist []string `yaml:"redirect_allowlist"`
+	// 初めてのOAuthログインでユーザー名の選択ステップを挟むか。falseの場合は名前から生成したユーザー名ですぐに登録する
+	ChooseUsername bool `yaml:"choose_username"`
 }
 
-func GetDB() *gorm.DB {
-	if DB == nil {
-		log.Fatal("database not initialized")
-	}
-	return DB
+// OAuthProviderConfig はGitHub・GoogleのOAuthアプリの設定です。
+type OAuthProviderConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	CallbackURL  string `yaml:"callback_url"`
 }
 
-func LoadEnv() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, relying on environment variables.")
-	}
+// Enabled はプロバイダーが設定されているかを返します。
+func (c OAuthProviderConfig) Enabled() bool {
+	return c.ClientID != ""
 }
 
-func GetGitHubAuthKey() string {
-	return os.Getenv("GITHUB_KEY")
+// OIDCProviderConfig は汎用OpenID Connectプロバイダーの設定です。
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"`       // プロバイダー名（/auth/{name} のパスに使用）
+	IssuerURL    string   `yaml:"issuer_url"` // {IssuerURL}/.well-known/openid-configuration からエンドポイントを取得
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	CallbackURL  string   `yaml:"callback_url"`
+	Scopes       []string `yaml:"scopes"` // 未設定の場合は openid, profile, email
+	// ユーザー情報を取り出すクレーム名（未指定の場合は標準クレームを使用）
+	UserIDClaim   string `yaml:"user_id_claim"`
+	UsernameClaim string `yaml:"username_claim"`
+	NameClaim     string `yaml:"name_claim"`
+	EmailClaim    string `yaml:"email_claim"`
 }
 
-func GetGitHubAuthSecret() string {
-	return os.Getenv("GITHUB_SECRET")
+// MailConfig はメール送信の設定です。
+type MailConfig struct {
+	Driver string     `yaml:"driver"` // log または smtp
+	From   string     `yaml:"from"`
+	LogDir string     `yaml:"log_dir"` // logドライバーでメールを.emlファイルとして保存するディレクトリ（任意）
+	SMTP   SMTPConfig `yaml:"smtp"`
 }
 
-func GetGitHubCallbackURL() string {
-	return os.Getenv("GITHUB_CALLBACK")
+// SMTPConfig はsmtpドライバーの設定です。
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
 
-func GetGoogleAuthKey() string {
-	return os.Getenv("GOOGLE_KEY")
+// PasswordResetConfig はパスワードリセットの設定です。
+type PasswordResetConfig struct {
+	TTL            time.Duration `yaml:"ttl"`
+	ResendCooldown time.Duration `yaml:"resend_cooldown"` // 同じユーザーにリセット用のメールを再び送信できるようになるまでの期間
 }
 
-func GetGoogleAuthSecret() string {
-	return os.Getenv("GOOGLE_SECRET")
+// EmailVerificationConfig はメールアドレス確認の設定です。
+type EmailVerificationConfig struct {
+	Secret         string        `yaml:"secret"` // 未設定の場合はjwt.access_secretから導出する
+	TTL            time.Duration `yaml:"ttl"`
+	ResendCooldown time.Duration `yaml:"resend_cooldown"`
 }
 
-func GetGoogleCallbackURL() string {
-	return os.Getenv("GOOGLE_CALLBACK")
+// UsernameConfig はユーザー名の設定です。
+type UsernameConfig struct {
+	ChangeCooldown time.Duration `yaml:"change_cooldown"` // ユーザー名を再び変更できるようになるまでの期間
 }
 
-// JWT設定
-func GetJWTAccessSecret() string {
-	secret := os.Getenv("JWT_ACCESS_SECRET")
-	if secret == "" {
-		secret = os.Getenv("JWT_SECRET") // 後方互換性
-	}
-	if secret == "" {
-		log.Fatal("JWT_ACCESS_SECRET or JWT_SECRET environment variable is required")
-	}
-	return secret
+// TOTPConfig は二要素認証の設定です。
+type TOTPConfig struct {
+	Issuer string `yaml:"issuer"`
 }
 
-func GetJWTRefreshSecret() string {
-	secret := os.Getenv("JWT_REFRESH_SECRET")
-	if secret == "" {
-		secret = GetJWTAccessSecret() // デフォルトはアクセスシークレットと同じ
-	}
-	return secret
+// HealthConfig はレディネスチェックの設定です。
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout"` // コンポーネントごとのチェックの制限時間
 }
 
-func GetJWTIssuer() string {
-	issuer := os.Getenv("JWT_ISSUER")
-	if issuer == "" {
-		issuer = "ctfforge"
+// MetricsConfig はPrometheusのメトリクス（/metrics）の設定です。
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"` // 設定した場合はこのアドレスで公開し、APIのポートでは公開しない
+	// 設定した場合は Authorization: Bearer <token> を要求する
+	BearerToken string `yaml:"bearer_token"`
+}
+
+// LogConfig はログ出力の設定です。
+type LogConfig struct {
+	Level string `yaml:"level"` // debug, info, warn, error
+}
+
+// SlogLevel はログレベルを返します。不正な値の場合はinfoを返します（Validateで検出）。
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return slog.LevelInfo
 	}
-	return issuer
+	return level
+}
+
+// TracingConfig はOpenTelemetryのトレースの設定です。
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`     // none（送信しない）, stdout（標準出力）, otlp（OTLP/HTTP）
+	Endpoint    string  `yaml:"endpoint"`     // OTLPの送信先（host:port）。空の場合はOTEL_EXPORTER_OTLP_ENDPOINTまたはlocalhost:4318
+	Insecure    bool    `yaml:"insecure"`     // OTLPをTLSなしで送信する
+	SampleRatio float64 `yaml:"sample_ratio"` // 0〜1。リクエストにトレースコンテキストがある場合は呼び出し元の判断に従う
+	ServiceName string  `yaml:"service_name"`
 }
 
-func GetJWTAccessExpireDuration() time.Duration {
-	hStr := os.Getenv("JWT_ACCESS_EXPIRE_HOURS")
-	h, err := strconv.Atoi(hStr)
-	if err != nil || h <= 0 {
-		h = 1 // デフォルト1時間
+// IsProduction は本番プロファイルかどうかを返します。
+func (c *Config) IsProduction() bool {
+	return c.Profile == ProfileProd
+}
+
+// Defaults は既定値の設定を返します。
+func Defaults() *Config {
+	return &Config{
+		Profile: ProfileDev,
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadTimeout:       time.Minute, // 問題ファイルのアップロードを考慮
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			AutoMigrate:     true,
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
+		},
+		JWT: JWTConfig{
+			Issuer:     "ctfforge",
+			AccessTTL:  time.Hour,
+			RefreshTTL: 7 * 24 * time.Hour,
+		},
+		FrontendURL: "http://localhost:5173",
+		OAuth:       OAuthConfig{ChooseUsername: true},
+		Mail: MailConfig{
+			Driver: "log", // 開発用（ログ出力のみ）
+			From:   "noreply@ctfforge.local",
+			SMTP:   SMTPConfig{Port: 587},
+		},
+		PasswordReset: PasswordResetConfig{
+			TTL:            time.Hour,
+			ResendCooldown: 5 * time.Minute,
+		},
+		EmailVerification: EmailVerificationConfig{
+			TTL:            24 * time.Hour,
+			ResendCooldown: time.Minute,
+		},
+		Username: UsernameConfig{ChangeCooldown: 30 * 24 * time.Hour},
+		TOTP:     TOTPConfig{Issuer: "CTFForge"},
+		Health:   HealthConfig{CheckTimeout: 2 * time.Second},
+		Metrics:  MetricsConfig{Enabled: true},
+		Log:      LogConfig{Level: "info"},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+			ServiceName: "ctfforge-backend",
+		},
 	}
-	return time.Duration(h) * time.Hour
 }
 
-func GetJWTRefreshExpireDuration() time.Duration {
-	hStr := os.Getenv("JWT_REFRESH_EXPIRE_HOURS")
-	h, err := strconv.Atoi(hStr)
-	if err != nil || h <= 0 {
-		h = 168 // デフォルト7日間（24 * 7）
+// Load は設定を読み込んで検証します。
+// .envファイルがあれば環境変数として読み込みます（既に設定されている環境変数は上書きしません）。
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		slog.Info("No .env file found, relying on environment variables")
+	}
+
+	profile := os.Getenv("APP_ENV")
+	explicit := profile != ""
+	if !explicit {
+		profile = ProfileDev
+	}
+	dir := os.Getenv("CONFIG_DIR")
+	if dir == "" {
+		dir = "config"
+	}
+
+	cfg := Defaults()
+	cfg.Profile = profile
+	if _, err := readYAML(cfg, filepath.Join(dir, "base.yaml")); err != nil {
+		return nil, err
+	}
+	found, err := readYAML(cfg, filepath.Join(dir, profile+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if !found && explicit {
+		return nil, fmt.Errorf("config file for profile %q not found in %s", profile, dir)
+	}
+
+	errs := applyEnv(cfg)
+	cfg.resolve()
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration (profile %s):\n%w", profile, errors.Join(errs...))
 	}
-	return time.Duration(h) * time.Hour
+	return cfg, nil
 }
 
-// 後方互換性のための関数
-func GetJWTSecret() string {
-	return GetJWTAccessSecret()
+// readYAML はYAMLファイルをcfgに上書きで読み込みます。ファイルが存在しない場合はfalseを返します。
+// 未知のキーは設定の誤りとしてエラーにします。
+func readYAML(cfg *Config, path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
 }
 
-func GetJWTExpireDuration() time.Duration {
-	return GetJWTAccessExpireDuration()
+// resolve は他の設定から決まる既定値を補完します。
+func (c *Config) resolve() {
+	c.FrontendURL = strings.TrimRight(c.FrontendURL, "/")
+	if c.JWT.RefreshSecret == "" {
+		c.JWT.RefreshSecret = c.JWT.AccessSecret
+	}
+	if c.EmailVerification.Secret == "" && c.JWT.AccessSecret != "" {
+		c.EmailVerification.Secret = deriveSecret(c.JWT.AccessSecret, "email_verification")
+	}
+	if len(c.OAuth.RedirectAllowlist) == 0 {
+		c.OAuth.RedirectAllowlist = []string{c.FrontendURL + "/auth/callback"}
+	}
+	for i := range c.OAuth.OIDC {
+		p := &c.OAuth.OIDC[i]
+		p.Name = strings.ToLower(p.Name)
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "profile", "email"}
+		}
+	}
 }
 
-func GetSessionSecret() string {
-	return os.Getenv("SESSION_SECRET")
+// deriveSecret はsecretから用途ごとの別のシークレットをHKDF-SHA256で導出します。
+// 同じシークレットで署名したトークンが別の用途に流用されないようにするために使用します。
+func deriveSecret(secret, purpose string) string {
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "ctfforge "+purpose, 32)
+	if err != nil {
+		panic(fmt.Sprintf("config: failed to derive %s secret: %v", purpose, err)) // 長さが固定のため発生しない
+	}
+	return hex.EncodeToString(key)
 }
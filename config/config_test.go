@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestResolveDerivesEmailVerificationSecret(t *testing.T) {
+	cfg := &Config{}
+	cfg.JWT.AccessSecret = "access-secret"
+	cfg.resolve()
+
+	derived := cfg.EmailVerification.Secret
+	if derived == "" || derived == cfg.JWT.AccessSecret {
+		t.Fatalf("email_verification.secret = %q, want a secret derived from but different from jwt.access_secret", derived)
+	}
+
+	// 同じaccess_secretからは同じシークレットを導出する（再起動後も確認リンクが有効）
+	again := &Config{}
+	again.JWT.AccessSecret = "access-secret"
+	again.resolve()
+	if again.EmailVerification.Secret != derived {
+		t.Errorf("derived secret changed between runs: %q != %q", again.EmailVerification.Secret, derived)
+	}
+
+	// 明示的に設定した場合はそのまま使用する
+	explicit := &Config{}
+	explicit.JWT.AccessSecret = "access-secret"
+	explicit.EmailVerification.Secret = "email-secret"
+	explicit.resolve()
+	if explicit.EmailVerification.Secret != "email-secret" {
+		t.Errorf("email_verification.secret = %q, want the configured value", explicit.EmailVerification.Secret)
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// slowQueryThreshold はこれより遅いクエリを警告としてログに出力する時間です。
+const slowQueryThreshold = time.Second
+
+// OpenDB はデータベースに接続し、接続プールを設定します。
+func OpenDB(cfg DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  cfg.URL,
+		PreferSimpleProtocol: true,
+	}), &gorm.Config{
+		Logger: logging.NewGormLogger(cfg.LogSQL, slowQueryThreshold),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generic database object: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	return db, nil
+}
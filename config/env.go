@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv は環境変数で設定を上書きします。設定されていない環境変数は無視し、値が不正なものはエラーとして返します。
+// 環境変数名はYAMLの導入前から使用しているものを維持しています。
+func applyEnv(c *Config) []error {
+	e := &envReader{}
+
+	e.string(&c.Server.Addr, "SERVER_ADDR")
+	e.string(&c.Server.TLSCertFile, "SERVER_TLS_CERT_FILE")
+	e.string(&c.Server.TLSKeyFile, "SERVER_TLS_KEY_FILE")
+
+	e.string(&c.Database.URL, "SUPABASE_URL")
+	e.bool(&c.Database.AutoMigrate, "DB_AUTO_MIGRATE")
+	e.bool(&c.Database.LogSQL, "DB_LOG_SQL")
+
+	e.string(&c.JWT.AccessSecret, "JWT_SECRET") // 後方互換性
+	e.string(&c.JWT.AccessSecret, "JWT_ACCESS_SECRET")
+	e.string(&c.JWT.RefreshSecret, "JWT_REFRESH_SECRET")
+	e.string(&c.JWT.Issuer, "JWT_ISSUER")
+	e.duration(&c.JWT.AccessTTL, "JWT_ACCESS_EXPIRE_HOURS", time.Hour)
+	e.duration(&c.JWT.RefreshTTL, "JWT_REFRESH_EXPIRE_HOURS", time.Hour)
+
+	e.string(&c.Session.Secret, "SESSION_SECRET")
+	e.string(&c.FrontendURL, "FRONTEND_URL")
+
+	e.string(&c.OAuth.GitHub.ClientID, "GITHUB_KEY")
+	e.string(&c.OAuth.GitHub.ClientSecret, "GITHUB_SECRET")
+	e.string(&c.OAuth.GitHub.CallbackURL, "GITHUB_CALLBACK")
+	e.string(&c.OAuth.Google.ClientID, "GOOGLE_KEY")
+	e.string(&c.OAuth.Google.ClientSecret, "GOOGLE_SECRET")
+	e.string(&c.OAuth.Google.CallbackURL, "GOOGLE_CALLBACK")
+	e.list(&c.OAuth.RedirectAllowlist, "OAUTH_REDIRECT_ALLOWLIST")
+	e.bool(&c.OAuth.ChooseUsername, "OAUTH_CHOOSE_USERNAME")
+	if names, ok := os.LookupEnv("OIDC_PROVIDERS"); ok {
+		c.OAuth.OIDC = oidcProvidersFromEnv(splitList(names))
+	}
+
+	e.string(&c.Mail.Driver, "MAIL_DRIVER")
+	e.string(&c.Mail.From, "MAIL_FROM")
+	e.string(&c.Mail.LogDir, "MAIL_LOG_DIR")
+	e.string(&c.Mail.SMTP.Host, "SMTP_HOST")
+	e.int(&c.Mail.SMTP.Port, "SMTP_PORT")
+	e.string(&c.Mail.SMTP.Username, "SMTP_USERNAME")
+	e.string(&c.Mail.SMTP.Password, "SMTP_PASSWORD")
+
+	e.duration(&c.PasswordReset.TTL, "PASSWORD_RESET_EXPIRE_MINUTES", time.Minute)
+	e.duration(&c.PasswordReset.ResendCooldown, "PASSWORD_RESET_RESEND_COOLDOWN_SECONDS", time.Second)
+
+	e.string(&c.EmailVerification.Secret, "EMAIL_VERIFICATION_SECRET")
+	e.duration(&c.EmailVerification.TTL, "EMAIL_VERIFICATION_EXPIRE_HOURS", time.Hour)
+	e.duration(&c.EmailVerification.ResendCooldown, "EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS", time.Second)
+
+	e.duration(&c.Username.ChangeCooldown, "USERNAME_CHANGE_COOLDOWN_DAYS", 24*time.Hour)
+
+	e.string(&c.TOTP.Issuer, "TOTP_ISSUER")
+
+	e.bool(&c.Metrics.Enabled, "METRICS_ENABLED")
+	e.string(&c.Metrics.Addr, "METRICS_ADDR")
+	e.string(&c.Metrics.BearerToken, "METRICS_BEARER_TOKEN")
+
+	e.string(&c.Log.Level, "LOG_LEVEL")
+
+	e.string(&c.Tracing.Exporter, "TRACING_EXPORTER")
+	e.string(&c.Tracing.Endpoint, "TRACING_OTLP_ENDPOINT")
+	e.bool(&c.Tracing.Insecure, "TRACING_OTLP_INSECURE")
+	e.float(&c.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO")
+	e.string(&c.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+
+	return e.errs
+}
+
+// oidcProvidersFromEnv はOIDC_PROVIDERSに列挙されたプロバイダーの設定を環境変数から読み込みます。
+// 各プロバイダーの設定は OIDC_{NAME}_ISSUER のようにプロバイダー名を大文字にした環境変数で指定します。
+func oidcProvidersFromEnv(names []string) []OIDCProviderConfig {
+	providers := make([]OIDCProviderConfig, 0, len(names))
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:          name,
+			IssuerURL:     os.Getenv(prefix + "ISSUER"),
+			ClientID:      os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:  os.Getenv(prefix + "CLIENT_SECRET"),
+			CallbackURL:   os.Getenv(prefix + "CALLBACK"),
+			Scopes:        splitList(os.Getenv(prefix + "SCOPES")),
+			UserIDClaim:   os.Getenv(prefix + "USER_ID_CLAIM"),
+			UsernameClaim: os.Getenv(prefix + "USERNAME_CLAIM"),
+			NameClaim:     os.Getenv(prefix + "NAME_CLAIM"),
+			EmailClaim:    os.Getenv(prefix + "EMAIL_CLAIM"),
+		})
+	}
+	return providers
+}
+
+// envReader は環境変数を型に応じて読み込み、不正な値のエラーを蓄積します。
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) lookup(name string) (string, bool) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", false
+	}
+	return strings.TrimSpace(v), true
+}
+
+func (e *envReader) string(dst *string, name string) {
+	if v, ok := e.lookup(name); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) bool(dst *bool, name string) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be true or false, got %q", name, v))
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) int(dst *int, name string) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be an integer, got %q", name, v))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) float(dst *float64, name string) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be a number, got %q", name, v))
+		return
+	}
+	*dst = f
+}
+
+// duration はunitを単位とする整数の環境変数を読み込みます（例: JWT_ACCESS_EXPIRE_HOURS=1）。
+func (e *envReader) duration(dst *time.Duration, name string, unit time.Duration) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be an integer, got %q", name, v))
+		return
+	}
+	*dst = time.Duration(n) * unit
+}
+
+func (e *envReader) list(dst *[]string, name string) {
+	if v, ok := e.lookup(name); ok {
+		*dst = splitList(v)
+	}
+}
+
+// splitList はカンマまたは空白区切りの文字列を分割します。
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+// minProdSecretLength は本番プロファイルで要求するシークレットの最小の長さです。
+const minProdSecretLength = 32
+
+var oidcNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Validate は設定を検証し、見つかった問題をすべて返します。項目名はYAMLのキーで表します。
+func (c *Config) Validate() []error {
+	v := &validator{}
+
+	v.require(c.Server.Addr, "server.addr")
+	v.check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	v.check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	v.check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	v.check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		v.errorf("server.tls_cert_file and server.tls_key_file must be set together")
+	}
+	if c.Server.TLSEnabled() {
+		v.file(c.Server.TLSCertFile, "server.tls_cert_file")
+		v.file(c.Server.TLSKeyFile, "server.tls_key_file")
+	}
+
+	v.require(c.Database.URL, "database.url (SUPABASE_URL)")
+	v.check(c.Database.MaxOpenConns > 0, "database.max_open_conns must be positive")
+	v.check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+
+	v.require(c.JWT.AccessSecret, "jwt.access_secret (JWT_ACCESS_SECRET)")
+	v.require(c.JWT.Issuer, "jwt.issuer")
+	v.check(c.JWT.AccessTTL > 0, "jwt.access_ttl must be positive")
+	v.check(c.JWT.RefreshTTL > 0, "jwt.refresh_ttl must be positive")
+
+	v.httpURL(c.FrontendURL, "frontend_url")
+
+	v.oauthProvider(c.OAuth.GitHub, "oauth.github")
+	v.oauthProvider(c.OAuth.Google, "oauth.google")
+	names := make(map[string]bool)
+	for i, p := range c.OAuth.OIDC {
+		key := fmt.Sprintf("oauth.oidc[%d]", i)
+		if !oidcNamePattern.MatchString(p.Name) {
+			v.errorf("%s.name must consist of lowercase letters, digits and hyphens, got %q", key, p.Name)
+		}
+		if p.Name == "github" || p.Name == "google" || names[p.Name] {
+			v.errorf("%s.name %q is already used by another provider", key, p.Name)
+		}
+		names[p.Name] = true
+		v.httpURL(p.IssuerURL, key+".issuer_url")
+		v.require(p.ClientID, key+".client_id")
+		v.httpURL(p.CallbackURL, key+".callback_url")
+	}
+	for i, uri := range c.OAuth.RedirectAllowlist {
+		v.absoluteURL(uri, fmt.Sprintf("oauth.redirect_allowlist[%d]", i))
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		v.require(c.Mail.SMTP.Host, "mail.smtp.host")
+		v.check(c.Mail.SMTP.Port > 0 && c.Mail.SMTP.Port <= 65535, "mail.smtp.port must be between 1 and 65535")
+	default:
+		v.errorf("mail.driver must be log or smtp, got %q", c.Mail.Driver)
+	}
+	v.require(c.Mail.From, "mail.from")
+
+	v.check(c.PasswordReset.TTL > 0, "password_reset.ttl must be positive")
+	v.check(c.PasswordReset.ResendCooldown >= 0, "password_reset.resend_cooldown must not be negative")
+	v.check(c.EmailVerification.Secret != c.JWT.AccessSecret, "email_verification.secret must differ from jwt.access_secret")
+	v.check(c.EmailVerification.TTL > 0, "email_verification.ttl must be positive")
+	v.check(c.EmailVerification.ResendCooldown >= 0, "email_verification.resend_cooldown must not be negative")
+	v.check(c.Username.ChangeCooldown >= 0, "username.change_cooldown must not be negative")
+	v.require(c.TOTP.Issuer, "totp.issuer")
+	v.check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	if c.Metrics.Enabled && c.Metrics.Addr != "" && c.Metrics.Addr == c.Server.Addr {
+		v.errorf("metrics.addr must differ from server.addr")
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		v.errorf("log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		v.errorf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	v.require(c.Tracing.ServiceName, "tracing.service_name")
+
+	if c.IsProduction() {
+		v.require(c.Session.Secret, "session.secret (SESSION_SECRET)")
+		v.secret(c.JWT.AccessSecret, "jwt.access_secret")
+		v.secret(c.JWT.RefreshSecret, "jwt.refresh_secret")
+		v.secret(c.EmailVerification.Secret, "email_verification.secret")
+		v.secret(c.Session.Secret, "session.secret")
+		v.check(c.Mail.Driver == "smtp", "mail.driver must be smtp in production")
+		if c.Metrics.Enabled && c.Metrics.Addr == "" && c.Metrics.BearerToken == "" {
+			v.errorf("metrics.bearer_token (METRICS_BEARER_TOKEN) or metrics.addr is required in production to protect /metrics")
+		}
+		if u, err := url.Parse(c.FrontendURL); err == nil && u.Scheme != "https" {
+			v.errorf("frontend_url must use https in production")
+		}
+	}
+	return v.errs
+}
+
+// validator は検証エラーを蓄積します。
+type validator struct {
+	errs []error
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) check(ok bool, msg string) {
+	if !ok {
+		v.errorf("%s", msg)
+	}
+}
+
+func (v *validator) require(value, key string) {
+	if value == "" {
+		v.errorf("%s is required", key)
+	}
+}
+
+func (v *validator) absoluteURL(value, key string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		v.errorf("%s must be an absolute URL, got %q", key, value)
+	}
+}
+
+func (v *validator) httpURL(value, key string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.errorf("%s must be an http or https URL, got %q", key, value)
+	}
+}
+
+// oauthProvider はclient_idが設定されている場合にほかの項目を検証します。
+func (v *validator) oauthProvider(p OAuthProviderConfig, key string) {
+	if !p.Enabled() {
+		return
+	}
+	v.require(p.ClientSecret, key+".client_secret")
+	v.httpURL(p.CallbackURL, key+".callback_url")
+}
+
+func (v *validator) secret(value, key string) {
+	if value != "" && len(value) < minProdSecretLength {
+		v.errorf("%s must be at least %d characters in production", key, minProdSecretLength)
+	}
+}
+
+func (v *validator) file(path, key string) {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		v.errorf("%s must be a readable file, got %q", key, path)
+	}
+}
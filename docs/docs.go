@@ -24,83 +24,90 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/challenges": {
-            "post": {
+        "/api/admin/users": {
+            "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "認証されたユーザーが新しい問題を作成します",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "ユーザー名・メールアドレスの部分一致でユーザーを検索します",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
                 ],
-                "summary": "新しい問題を作成",
+                "summary": "ユーザー一覧取得（管理者）",
                 "parameters": [
                     {
-                        "description": "問題作成情報",
-                        "name": "challenge",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.CreateChallengeRequest"
-                        }
+                        "type": "string",
+                        "description": "検索キーワード",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ページ番号",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "1ページあたりの件数",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ChallengeCreateResponse"
+                            "$ref": "#/definitions/dtos.UserListResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/api/challenges/{challengeId}": {
+        "/api/admin/users/{userId}": {
             "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "問題IDを指定して、問題の詳細を取得します（所有者のみフラグ表示）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
                 ],
-                "summary": "問題詳細を取得",
+                "summary": "ユーザー詳細取得（管理者）",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Challenge ID",
-                        "name": "challengeId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
@@ -109,74 +116,62 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ChallengeDetailResponse"
+                            "$ref": "#/definitions/dtos.AdminUserDTO"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "既存の問題を更新します",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "ユーザーと、そのユーザーの問題・提出履歴・OAuthアカウントを削除します",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
                 ],
-                "summary": "問題を更新",
+                "summary": "ユーザー削除（管理者）",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Challenge ID",
-                        "name": "challengeId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "問題更新情報",
-                        "name": "challenge",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.UpdateChallengeRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -189,42 +184,44 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/users/{userId}/ban": {
+            "post": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "既存の問題を削除します",
+                "description": "BANされたユーザーはログイン・トークン更新・フラグ提出ができなくなります",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,16 +229,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
                 ],
-                "summary": "問題を削除",
+                "summary": "ユーザーをBAN（管理者）",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Challenge ID",
-                        "name": "challengeId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "BAN理由",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BanUserRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -254,215 +259,262 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/api/challenges/{challengeId}/submit": {
-            "post": {
+        "/api/admin/users/{userId}/challenges": {
+            "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "問題にフラグを提出し、正解かどうかを検証します",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "非公開の問題も含めて、指定ユーザーが作成した問題を返します",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
                 ],
-                "summary": "フラグを提出",
+                "summary": "ユーザーの作成問題一覧（管理者）",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Challenge ID",
-                        "name": "challengeId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "提出情報",
-                        "name": "submission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.SubmissionRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "提出結果",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.SubmissionResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Challenge"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/api/me": {
-            "get": {
+        "/api/admin/users/{userId}/password-reset": {
+            "post": {
                 "security": [
                     {
                         "bearer": []
                     }
                 ],
-                "description": "JWT認証ユーザーの情報を返す",
+                "description": "ユーザーはパスワードを再設定するまでパスワードでログインできなくなります",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
+                ],
+                "summary": "パスワード再設定の強制（管理者）",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "自分のユーザー情報取得",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.MeResponse"
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/api/me/challenges": {
-            "get": {
+        "/api/admin/users/{userId}/role": {
+            "put": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "bearer": []
                     }
                 ],
-                "description": "認証されたユーザーが作成した問題のリストを取得します",
+                "description": "ロールを変更したユーザーのセッションはすべて失効し、新しいロールは次回のログインから反映されます。自分自身のロールは変更できません",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "challenges"
+                    "admin"
+                ],
+                "summary": "ユーザーのロール変更（管理者）",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ロール（user または admin）",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SetUserRoleRequest"
+                        }
+                    }
                 ],
-                "summary": "ユーザーが作成した問題を取得",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Challenge"
-                            }
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
-                    }
-                }
-            }
-        },
-        "/api/public/challenges": {
-            "get": {
-                "description": "公開されているすべての問題のリストを取得します",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "public_challenges"
-                ],
-                "summary": "公開されているすべての問題を取得",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.ChallengePublicDTO"
-                            }
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/api/public/challenges/{challengeId}": {
+        "/api/admin/users/{userId}/submissions": {
             "get": {
-                "description": "問題IDを指定して、公開用の問題詳細を取得します",
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "public_challenges"
+                    "admin"
                 ],
-                "summary": "公開用の問題詳細を取得",
+                "summary": "ユーザーの提出履歴（管理者）",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Challenge ID",
-                        "name": "challengeId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
@@ -471,125 +523,154 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ChallengePublicDTO"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.SubmissionDTO"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/login": {
+        "/api/admin/users/{userId}/unban": {
             "post": {
-                "description": "メールとパスワードでログインします",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "bearer": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "ユーザーログイン",
+                "summary": "ユーザーのBAN解除（管理者）",
                 "parameters": [
                     {
-                        "description": "ログイン情報",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.TokenResponse"
+                            "$ref": "#/definitions/handler.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "description": "ユーザーログアウトします",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "ログアウト",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.MessageResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/oauth/logout": {
-            "post": {
-                "description": "OAuthログアウト処理を行います",
-                "consumes": [
-                    "application/json"
+        "/api/challenges": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "認証されたユーザーが作成した問題（非公開を含む）のリストを取得します",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "challenges"
                 ],
-                "summary": "OAuthログアウト",
+                "summary": "自分が作成した問題を取得",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.MessageResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Challenge"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
-            }
-        },
-        "/auth/oauth/refresh": {
+            },
             "post": {
-                "description": "リフレッシュトークンを使用して新しいトークンペアを生成します",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "認証されたユーザーが新しい問題を作成します",
                 "consumes": [
                     "application/json"
                 ],
@@ -597,91 +678,124 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "challenges"
                 ],
-                "summary": "OAuthトークン更新",
+                "summary": "新しい問題を作成",
                 "parameters": [
                     {
-                        "description": "リフレッシュトークン",
-                        "name": "body",
+                        "description": "問題作成情報",
+                        "name": "challenge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                            "$ref": "#/definitions/dtos.CreateChallengeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.TokenResponse"
+                            "$ref": "#/definitions/dtos.ChallengeCreateResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "リフレッシュトークンを使用して新しいアクセストークンを取得します",
-                "consumes": [
-                    "application/json"
+        "/api/challenges/{challengeId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "問題IDを指定して、問題の詳細を取得します（所有者と管理者のみフラグ表示）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "challenges"
                 ],
-                "summary": "トークン更新",
+                "summary": "問題詳細を取得",
                 "parameters": [
                     {
-                        "description": "リフレッシュトークン",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.RefreshTokenRequest"
-                        }
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challengeId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.TokenResponse"
+                            "$ref": "#/definitions/dtos.ChallengeDetailResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "新規ユーザーを登録します",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "既存の問題を更新します（管理者は他のユーザーの問題も更新・非公開化できます）",
                 "consumes": [
                     "application/json"
                 ],
@@ -689,23 +803,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "challenges"
                 ],
-                "summary": "ユーザー登録",
+                "summary": "問題を更新",
                 "parameters": [
                     {
-                        "description": "登録情報",
-                        "name": "body",
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challengeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "問題更新情報",
+                        "name": "challenge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.RegisterRequest"
+                            "$ref": "#/definitions/dtos.UpdateChallengeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/handler.MessageResponse"
                         }
@@ -713,82 +834,57 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/{provider}": {
-            "get": {
-                "description": "指定したプロバイダーでOAuth認証を開始します",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "oauth"
-                ],
-                "summary": "OAuth認証開始",
-                "parameters": [
-                    {
-                        "enum": [
-                            "github",
-                            "google"
-                        ],
-                        "type": "string",
-                        "description": "プロバイダー名",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "307": {
-                        "description": "リダイレクト",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     }
                 }
-            }
-        },
-        "/auth/{provider}/callback": {
-            "get": {
-                "description": "OAuth認証のコールバックを処理し、JWTトークンを発行します",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "既存の問題を削除します",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "challenges"
                 ],
-                "summary": "OAuth認証コールバック",
+                "summary": "問題を削除",
                 "parameters": [
                     {
-                        "enum": [
-                            "github",
-                            "google"
-                        ],
-                        "type": "string",
-                        "description": "プロバイダー名",
-                        "name": "provider",
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challengeId",
                         "in": "path",
                         "required": true
                     }
@@ -797,37 +893,2347 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.OAuthResponse"
+                            "$ref": "#/definitions/handler.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProblemDetails"
                         }
-                    }
-                }
-            }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/challenges/{challengeId}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "問題にフラグを提出し、正解かどうかを検証します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "フラグを提出",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challengeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "提出情報",
+                        "name": "submission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SubmissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "提出結果",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SubmissionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "JWT認証ユーザーの情報を返す",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "自分のユーザー情報取得",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "ユーザー名・表示名・自己紹介・アイコン画像のURL・解いた問題の公開設定を更新します。省略した項目は変更しません。ユーザー名は一定期間に1回のみ変更できます",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "プロフィールの更新",
+                "parameters": [
+                    {
+                        "description": "プロフィール",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "現在のコードを確認してリカバリーコードを再発行します。以前のリカバリーコードは無効になります",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "リカバリーコードの再発行",
+                "parameters": [
+                    {
+                        "description": "TOTPコード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "認証アプリのコードを確認して二要素認証を有効にし、リカバリーコードを返します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "二要素認証の有効化",
+                "parameters": [
+                    {
+                        "description": "TOTPコード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/totp/disable": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "TOTPコードまたはリカバリーコードを確認して二要素認証を無効にします",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "二要素認証の無効化",
+                "parameters": [
+                    {
+                        "description": "TOTPコードまたはリカバリーコード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/totp/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "TOTPシークレットを生成し、認証アプリ登録用のotpauth URIを返します。confirmで確認するまで有効になりません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "二要素認証の登録開始",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TOTPEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/email": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "新しいメールアドレスに確認リンクを送信します。リンクを開いて確認するまでメールアドレスは変更されません",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "メールアドレスの変更",
+                "parameters": [
+                    {
+                        "description": "新しいメールアドレス",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/email/verification": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "メールアドレスの確認メールを再送します。前回の送信から一定時間内は再送できません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "確認メールの再送",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/identities": {
+            "get": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "パスワードの設定有無と、連携済みのOAuthプロバイダーの一覧を返します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ログイン方法の一覧",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.IdentitiesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/identities/{identityId}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "連携済みのOAuthプロバイダーを解除します。最後のログイン方法は解除できません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "OAuthプロバイダーの連携解除",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Identity ID",
+                        "name": "identityId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/identities/{provider}": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "ログイン中のアカウントにOAuthプロバイダーを連携するための認可URLを返します。\n連携トークンはoauth_link_token Cookie（HttpOnly）で返すため、同じブラウザでauthorize_urlを開くと連携が完了します。\n別のブラウザや他のユーザーがauthorize_urlを開いても連携は行われません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "OAuthプロバイダーの連携開始",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "プロバイダー名（github, google, または設定したOIDCプロバイダー）",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "現在のパスワードを確認してパスワードを変更します。変更後は現在のセッション以外のセッションがすべて失効します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "パスワードの変更",
+                "parameters": [
+                    {
+                        "description": "現在のパスワードと新しいパスワード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "自分の有効なセッション（ログイン中の端末）の一覧を返します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ログイン中のセッション一覧",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.SessionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "現在のセッション以外のすべてのセッションからサインアウトします",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "他のセッションをすべて失効",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "指定したセッションからサインアウトします",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "セッションを失効",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "自分の有効なパーソナルアクセストークンの一覧を返します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "パーソナルアクセストークン一覧",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.APITokenDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "CLIや自動化用のスコープ付きトークンを発行します。トークン本体はこのレスポンスでのみ表示されます",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "パーソナルアクセストークンの発行",
+                "parameters": [
+                    {
+                        "description": "トークン情報",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateAPITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.APITokenCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/tokens/{tokenId}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearer": []
+                    }
+                ],
+                "description": "指定したパーソナルアクセストークンを失効させます",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "パーソナルアクセストークンの失効",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "tokenId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/achievements": {
+            "get": {
+                "description": "解除できるすべての実績（バッジ）と解除条件をAccept-Languageの言語で返します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "実績の一覧",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.AchievementDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/challenges": {
+            "get": {
+                "description": "公開されているすべての問題のリストを取得します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public_challenges"
+                ],
+                "summary": "公開されているすべての問題を取得",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.ChallengePublicDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/challenges/{challengeId}": {
+            "get": {
+                "description": "問題IDを指定して、公開用の問題詳細を取得します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public_challenges"
+                ],
+                "summary": "公開用の問題詳細を取得",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challengeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChallengePublicDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/users/{username}": {
+            "get": {
+                "description": "ユーザーの公開プロフィール、作成した公開問題、解いた問題、合計スコア・順位、カテゴリー別の成績を返します。解いた問題を非表示にしているユーザーの場合、本人以外にはsolvesとcategoriesを返しません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "公開プロフィールの取得",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ユーザー名",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PublicProfileResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/users/{username}/challenges": {
+            "get": {
+                "description": "パスで指定したユーザーが作成した公開中の問題のリストを取得します。ログインしている場合はis_solvedに自分が解いたかどうかが入ります",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "ユーザーが作成した問題を取得",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ユーザー名",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.ChallengePublicDTO"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/email/change/confirm": {
+            "post": {
+                "description": "新しいメールアドレスに届いた確認リンクのトークンを検証し、メールアドレスを変更します。以前のメールアドレスには変更の通知が送信されます",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "メールアドレス変更の確定",
+                "parameters": [
+                    {
+                        "description": "確認トークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/email/verify": {
+            "post": {
+                "description": "確認メールのリンクに含まれるトークンを検証し、メールアドレスを確認済みにします",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "メールアドレスの確認",
+                "parameters": [
+                    {
+                        "description": "確認トークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "メールとパスワードでログインします。二要素認証が有効な場合はトークンの代わりにMFARequiredResponse（mfa_token）を返すので、/auth/login/mfaでコードを送信してください",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "ユーザーログイン",
+                "parameters": [
+                    {
+                        "description": "ログイン情報",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/mfa": {
+            "post": {
+                "description": "ログイン時に発行された二要素認証待ちトークンとTOTPコード（またはリカバリーコード）を検証し、トークンを発行します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "二要素認証ログイン",
+                "parameters": [
+                    {
+                        "description": "二要素認証情報",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MFALoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "リフレッシュトークンが属するセッションを失効させます",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "ログアウト",
+                "parameters": [
+                    {
+                        "description": "リフレッシュトークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/exchange": {
+            "post": {
+                "description": "OAuthログイン後にredirect_uriで受け取った認可コードをトークンペアと交換します。コードは1回のみ・発行から1分間有効です。\n二要素認証が有効なユーザーの場合はトークンの代わりにMFARequiredResponse（mfa_token）を返します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth認可コードの交換",
+                "parameters": [
+                    {
+                        "description": "認可コード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.OAuthExchangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/logout": {
+            "post": {
+                "description": "リフレッシュトークンが属するセッションを失効させます",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuthログアウト",
+                "parameters": [
+                    {
+                        "description": "リフレッシュトークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/refresh": {
+            "post": {
+                "description": "リフレッシュトークンを使用して新しいトークンペアを生成します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuthトークン更新",
+                "parameters": [
+                    {
+                        "description": "リフレッシュトークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/signup": {
+            "post": {
+                "description": "初めてのOAuthログインで返されたsignup_tokenとユーザー名を送信して登録を完了します。usernameを省略すると提案されたユーザー名を使用します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth新規登録の完了",
+                "parameters": [
+                    {
+                        "description": "登録情報",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.OAuthSignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/forgot": {
+            "post": {
+                "description": "登録済みのメールアドレスにパスワード再設定用のリンクを送信します。メールアドレスが未登録でも同じレスポンスを返します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "パスワードリセットの申請",
+                "parameters": [
+                    {
+                        "description": "メールアドレス",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "メールで受け取ったトークンを使ってパスワードを再設定します。再設定後は既存のセッションがすべて失効します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "パスワードの再設定",
+                "parameters": [
+                    {
+                        "description": "リセットトークンと新しいパスワード",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/providers": {
+            "get": {
+                "description": "ログインに使用できるOAuthプロバイダーの一覧を返します",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuthプロバイダー一覧",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.OAuthProvidersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "リフレッシュトークンを使用して新しいアクセストークンを取得します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "トークン更新",
+                "parameters": [
+                    {
+                        "description": "リフレッシュトークン",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "新規ユーザーを登録し、メールアドレスの確認メールを送信します",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "ユーザー登録",
+                "parameters": [
+                    {
+                        "description": "登録情報",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}": {
+            "get": {
+                "description": "指定したプロバイダーでOAuth認証を開始します。\nredirect_uriを指定した場合、コールバック後に認可コードを付けてリダイレクトします（POST /auth/oauth/exchangeでトークンと交換）。\nループバックアドレス（http://127.0.0.1:{port}/...）へのリダイレクトはcode_challenge（S256）が必須です。\nlink=1を指定した場合は、POST /api/me/identities/{provider}で連携トークンのCookieを受け取ったブラウザでのみ、ログイン中のアカウントへの連携として扱います",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth認証開始",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "プロバイダー名（github, google, または設定したOIDCプロバイダー）",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ログイン後のリダイレクト先（許可リストまたはループバックアドレス）",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCEのcode_challenge",
+                        "name": "code_challenge",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "S256"
+                        ],
+                        "type": "string",
+                        "description": "PKCEのcode_challenge_method",
+                        "name": "code_challenge_method",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "1"
+                        ],
+                        "type": "string",
+                        "description": "ログイン中のアカウントへの連携",
+                        "name": "link",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "リダイレクト",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/callback": {
+            "get": {
+                "description": "OAuth認証のコールバックを処理し、JWTトークンを発行します。\n二要素認証が有効なユーザーの場合はトークンの代わりにMFARequiredResponse（mfa_token）を返すので、/auth/login/mfaでコードを送信してください。\n認証開始時にredirect_uriを指定した場合は、トークンの代わりに認可コード（code）を付けてredirect_uriへリダイレクトします。\nエラー時はerrorとerror_descriptionを付けてリダイレクトします",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth認証コールバック",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "プロバイダー名（github, google, または設定したOIDCプロバイダー）",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.OAuthResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "redirect_uriへリダイレクト",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "プロセスが応答できるかどうかを返します。依存するサービスの状態は確認しません",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "ライブネスチェック",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LivenessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "データベースなどの登録されたコンポーネントを確認し、コンポーネントごとの状態を返します。必須のコンポーネントが1つでも失敗した場合は503を返します。必須でないコンポーネントの失敗はstatusがdegradedになります",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "レディネスチェック",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "dtos.APITokenCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string",
+                    "example": "ctfp_q3Xk..."
+                },
+                "token_prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.APITokenDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AchievementDTO": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Be the first to solve a challenge"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "first_blood"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "First Blood"
+                },
+                "unlocked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AdminUserDTO": {
+            "type": "object",
+            "properties": {
+                "ban_reason": {
+                    "type": "string"
+                },
+                "banned_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "password_reset_required": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AuthoredChallengeDTO": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.BanUserRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CategoryStatDTO": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "web"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "solved": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "total": {
+                    "description": "カテゴリーの公開問題数",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
         "dtos.ChallengeCreateResponse": {
             "type": "object",
             "properties": {
+                "id": {
+                    "type": "integer"
+                },
                 "message": {
                     "type": "string"
+                },
+                "message_key": {
+                    "type": "string",
+                    "example": "messages.challenge_created"
                 }
             }
         },
@@ -875,91 +3281,461 @@ const docTemplate = `{
                 "score": {
                     "type": "integer"
                 },
-                "title": {
-                    "type": "string"
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CreateAPITokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "0の場合は無期限",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "ci-upload"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "challenges:write",
+                        "submit"
+                    ]
+                }
+            }
+        },
+        "dtos.CreateChallengeRequest": {
+            "type": "object",
+            "required": [
+                "flag",
+                "title"
+            ],
+            "properties": {
+                "category": {
+                    "description": "カテゴリー名を文字列として受け取ります",
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "flag": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "dtos.IdentitiesResponse": {
+            "type": "object",
+            "properties": {
+                "has_password": {
+                    "description": "メールアドレスとパスワードでログインできるか",
+                    "type": "boolean"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.IdentityDTO"
+                    }
+                }
+            }
+        },
+        "dtos.IdentityDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "linked_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "github"
+                },
+                "provider_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.OAuthLinkResponse": {
+            "type": "object",
+            "properties": {
+                "authorize_url": {
+                    "type": "string",
+                    "example": "/auth/github?link=1"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
+                }
+            }
+        },
+        "dtos.PublicProfileResponse": {
+            "type": "object",
+            "properties": {
+                "achievements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AchievementDTO"
+                    }
+                },
+                "authored_challenges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AuthoredChallengeDTO"
+                    }
+                },
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://example.com/avatar.png"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Web/Crypto好きです"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.CategoryStatDTO"
+                    }
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Test User"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "スコアが0の場合はnull",
+                    "type": "integer",
+                    "example": 3
+                },
+                "solve_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "solves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.SolveDTO"
+                    }
+                },
+                "solves_hidden": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total_score": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "username": {
+                    "type": "string",
+                    "example": "testuser"
+                }
+            }
+        },
+        "dtos.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.SessionDTO": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "description": "リクエストに使用したアクセストークンのセッションかどうか",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "signed_in_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SetUserRoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "user または admin",
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "dtos.SolveDTO": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "challenge_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "solved_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SubmissionDTO": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "integer"
+                },
+                "challenge_title": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "submitted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SubmissionRequest": {
+            "type": "object",
+            "required": [
+                "flag"
+            ],
+            "properties": {
+                "flag": {
+                    "type": "string",
+                    "maxLength": 256
+                }
+            }
+        },
+        "dtos.SubmissionResponse": {
+            "type": "object",
+            "properties": {
+                "correct": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "description": "リクエストの言語の結果のメッセージ",
+                    "type": "string"
+                },
+                "message_key": {
+                    "description": "submission.correct または submission.incorrect",
+                    "type": "string",
+                    "example": "submission.correct"
+                },
+                "unlocked_achievements": {
+                    "description": "この提出で新しく解除した実績",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AchievementDTO"
+                    }
+                }
+            }
+        },
+        "dtos.TOTPEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "description": "QRコード用",
+                    "type": "string",
+                    "example": "otpauth://totp/CTFForge:testuser?issuer=CTFForge\u0026secret=JBSWY3DPEHPK3PXP"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "dtos.UpdateChallengeRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "flag": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "dtos.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "https://example.com/avatar.png"
+                },
+                "bio": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Web/Crypto好きです"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "New Name"
+                },
+                "hide_solves": {
+                    "description": "公開プロフィールで解いた問題を非表示にする",
+                    "type": "boolean",
+                    "example": false
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 3,
+                    "example": "newname"
                 }
             }
         },
-        "dtos.CreateChallengeRequest": {
+        "dtos.UserListResponse": {
             "type": "object",
-            "required": [
-                "flag",
-                "score",
-                "title"
-            ],
             "properties": {
-                "category": {
-                    "description": "カテゴリー名を文字列として受け取ります",
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "flag": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "is_public": {
-                    "type": "boolean"
+                "page": {
+                    "type": "integer"
                 },
-                "score": {
+                "total": {
                     "type": "integer"
                 },
-                "title": {
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AdminUserDTO"
+                    }
                 }
             }
         },
-        "dtos.SubmissionRequest": {
+        "handler.ChangeEmailRequest": {
             "type": "object",
             "required": [
-                "flag"
+                "new_email"
             ],
             "properties": {
-                "flag": {
-                    "type": "string"
+                "current_password": {
+                    "description": "パスワードが設定されているアカウントでは必須",
+                    "type": "string",
+                    "example": "password123"
+                },
+                "new_email": {
+                    "type": "string",
+                    "example": "new@example.com"
                 }
             }
         },
-        "dtos.SubmissionResponse": {
+        "handler.ChangePasswordRequest": {
             "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
             "properties": {
-                "correct": {
-                    "type": "boolean"
+                "current_password": {
+                    "type": "string",
+                    "example": "password123"
                 },
-                "message": {
-                    "type": "string"
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "newpassword123"
                 }
             }
         },
-        "dtos.UpdateChallengeRequest": {
+        "handler.ForgotPasswordRequest": {
             "type": "object",
+            "required": [
+                "email"
+            ],
             "properties": {
-                "category": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "flag": {
-                    "type": "string"
-                },
-                "is_public": {
-                    "type": "boolean"
-                },
-                "score": {
-                    "type": "integer"
-                },
-                "title": {
-                    "type": "string"
+                "email": {
+                    "type": "string",
+                    "example": "test@example.com"
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "handler.LivenessResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "status": {
                     "type": "string",
-                    "example": "error message"
+                    "example": "ok"
                 }
             }
         },
@@ -980,18 +3756,68 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.MFALoginRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
         "handler.MeResponse": {
             "description": "自分のユーザー情報",
             "type": "object",
             "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://example.com/avatar.png"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Web/Crypto好きです"
+                },
                 "created_at": {
                     "type": "string",
                     "example": "2024-08-01T12:34:56Z"
                 },
+                "display_name": {
+                    "type": "string",
+                    "example": "Test User"
+                },
                 "email": {
                     "type": "string",
                     "example": "test@example.com"
                 },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "hide_solves": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "pending_email": {
+                    "description": "確認待ちの新しいメールアドレス",
+                    "type": "string",
+                    "example": "new@example.com"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "totp_enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "user_id": {
                     "type": "integer",
                     "example": 1
@@ -1006,8 +3832,51 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "message": {
+                    "description": "リクエストの言語のメッセージ",
                     "type": "string",
-                    "example": "success message"
+                    "example": "Signed out successfully."
+                },
+                "message_key": {
+                    "type": "string",
+                    "example": "messages.logout_successful"
+                }
+            }
+        },
+        "handler.OAuthExchangeRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "redirect_uri"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "q3Xk9a..."
+                },
+                "code_verifier": {
+                    "description": "PKCEを使用した場合は必須",
+                    "type": "string",
+                    "example": "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "http://localhost:5173/auth/callback"
+                }
+            }
+        },
+        "handler.OAuthProvidersResponse": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "github",
+                        "google",
+                        "keycloak"
+                    ]
                 }
             }
         },
@@ -1024,7 +3893,11 @@ const docTemplate = `{
                 },
                 "message": {
                     "type": "string",
-                    "example": "oauth authentication successful"
+                    "example": "Signed in with OAuth successfully."
+                },
+                "message_key": {
+                    "type": "string",
+                    "example": "messages.oauth_login_successful"
                 },
                 "refresh_token": {
                     "type": "string",
@@ -1049,6 +3922,75 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.OAuthSignupRequest": {
+            "type": "object",
+            "required": [
+                "signup_token"
+            ],
+            "properties": {
+                "code_verifier": {
+                    "description": "PKCEを使用した場合は必須",
+                    "type": "string",
+                    "example": "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+                },
+                "signup_token": {
+                    "type": "string",
+                    "example": "q3Xk9a..."
+                },
+                "username": {
+                    "description": "省略時は提案されたユーザー名を使用",
+                    "type": "string",
+                    "example": "jose_garcia"
+                }
+            }
+        },
+        "handler.ProblemDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "機械判定用の識別子",
+                    "type": "string",
+                    "example": "challenge_not_found"
+                },
+                "detail": {
+                    "description": "リクエストの言語のメッセージ",
+                    "type": "string",
+                    "example": "Challenge not found."
+                },
+                "errors": {
+                    "description": "入力の検証エラー（400の場合のみ）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/challenges/42"
+                },
+                "message_key": {
+                    "description": "detailのメッセージのキー",
+                    "type": "string",
+                    "example": "errors.challenge_not_found"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "3f2a9c1e8b7d4a6f0e5c2b1a9d8c7e6f"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
         "handler.RefreshTokenRequest": {
             "type": "object",
             "required": [
@@ -1086,6 +4028,36 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "newpassword123"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q1w2e3r4t5y6..."
+                }
+            }
+        },
+        "handler.TOTPCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
         "handler.TokenResponse": {
             "type": "object",
             "properties": {
@@ -1099,7 +4071,11 @@ const docTemplate = `{
                 },
                 "message": {
                     "type": "string",
-                    "example": "login successful"
+                    "example": "Signed in successfully."
+                },
+                "message_key": {
+                    "type": "string",
+                    "example": "messages.login_successful"
                 },
                 "refresh_token": {
                     "type": "string",
@@ -1107,6 +4083,55 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "health.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "内部の情報を含まない概要。詳細はサーバーのログに出力",
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.ComponentStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "models.Challenge": {
             "type": "object",
             "properties": {
@@ -1159,20 +4184,111 @@ const docTemplate = `{
         "models.User": {
             "type": "object",
             "properties": {
+                "avatarURL": {
+                    "description": "アイコン画像のURL",
+                    "type": "string"
+                },
+                "banReason": {
+                    "type": "string"
+                },
+                "bannedAt": {
+                    "type": "string"
+                },
+                "bio": {
+                    "description": "自己紹介",
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
+                "displayName": {
+                    "description": "表示名",
+                    "type": "string"
+                },
                 "email": {
+                    "description": "OAuthで取得できない場合は空。空でない値だけ一意（部分インデックスidx_users_email）",
+                    "type": "string"
+                },
+                "emailVerified": {
+                    "type": "boolean"
+                },
+                "emailVerifiedAt": {
                     "type": "string"
                 },
+                "hideSolves": {
+                    "description": "公開プロフィールで解いた問題を非表示にする",
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "passwordHash": {
                     "type": "string"
                 },
+                "passwordResetRequired": {
+                    "type": "boolean"
+                },
+                "pendingEmail": {
+                    "description": "確認待ちの新しいメールアドレス",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "totpenabled": {
+                    "type": "boolean"
+                },
+                "totpenabledAt": {
+                    "type": "string"
+                },
+                "totpfailedAttempts": {
+                    "description": "続けてコードを誤った回数",
+                    "type": "integer"
+                },
+                "totplastUsedStep": {
+                    "description": "同じコードの再利用防止",
+                    "type": "integer"
+                },
+                "totplockedUntil": {
+                    "description": "コードの誤りによるロックの期限",
+                    "type": "string"
+                },
+                "totpsecret": {
+                    "description": "二要素認証のシークレット（base32）。有効化前は登録中のシークレット",
+                    "type": "string"
+                },
                 "username": {
                     "type": "string"
+                },
+                "usernameChangedAt": {
+                    "description": "ユーザー名の最終変更日時（変更間隔の制限に使用）",
+                    "type": "string"
+                },
+                "verificationSentAt": {
+                    "description": "確認メールの最終送信日時（再送の制限に使用）",
+                    "type": "string"
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "検証のルール名",
+                    "type": "string",
+                    "example": "min"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "username"
+                },
+                "message": {
+                    "description": "リクエストの言語のメッセージ",
+                    "type": "string",
+                    "example": "username must be at least 3 characters in length"
                 }
             }
         }
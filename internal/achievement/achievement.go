@@ -0,0 +1,119 @@
+// Package achievement は実績（バッジ）のルールとその登録先を提供します。
+//
+// ルールはコードで宣言してRegistryに登録します。フラグ提出や問題公開などのイベントの後に
+// Registry.Evaluateを呼び出すと、まだ解除されていないルールのうち条件を満たしたものが返されます。
+// ルールはStatsインターフェース経由でのみ成績を参照するため、DBなしで判定を確認できます。
+// 表示名と解除条件の説明はメッセージカタログのキーで持ち、言語ごとの文言は呼び出し側で作成します。
+package achievement
+
+import (
+	"context"
+	"fmt"
+)
+
+// Event は実績の判定のきっかけとなるイベントです。
+type Event string
+
+const (
+	EventSolve   Event = "solve"   // フラグの正解
+	EventPublish Event = "publish" // 問題の公開
+)
+
+// Stats はルールの判定に使用するユーザーの成績です。
+type Stats interface {
+	// SolveCount は解いた公開問題の数を返します。
+	SolveCount(ctx context.Context) (int, error)
+	// CategorySolveCount は指定したカテゴリー（大文字・小文字を区別しない）で解いた公開問題の数を返します。
+	CategorySolveCount(ctx context.Context, category string) (int, error)
+	// IsFirstBlood は問題を作成者以外で最初に解いたユーザーかどうかを返します。
+	IsFirstBlood(ctx context.Context, challengeID uint) (bool, error)
+	// PublishedCount は作成した公開問題の数を返します。
+	PublishedCount(ctx context.Context) (int, error)
+}
+
+// Input はルールの判定に渡す情報です。
+type Input struct {
+	UserID      uint
+	Event       Event
+	ChallengeID uint // イベントの対象の問題
+	Stats       Stats
+}
+
+// Rule は実績の定義と解除条件です。
+type Rule struct {
+	ID             string            // 保存に使用する識別子。公開後は変更しないでください
+	DescriptionKey string            // 解除条件の説明のメッセージキー
+	Params         map[string]string // 解除条件の説明に埋め込む値
+	Events         []Event           // 判定するイベント
+	Check          func(ctx context.Context, in *Input) (bool, error)
+}
+
+// NameKey は表示名のメッセージキー（achievements.names.<ID>）を返します。
+func (r *Rule) NameKey() string {
+	return "achievements.names." + r.ID
+}
+
+// Handles はイベントがルールの判定対象かどうかを返します。
+func (r *Rule) Handles(event Event) bool {
+	for _, e := range r.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry は実績のルールの登録先です。
+type Registry struct {
+	rules []*Rule
+	byID  map[string]*Rule
+}
+
+// NewRegistry は空のRegistryを作成します。
+func NewRegistry() *Registry {
+	return &Registry{byID: make(map[string]*Rule)}
+}
+
+// Register はルールを登録します。
+// IDが空・重複している場合やCheckが未設定の場合はプログラムの誤りのためpanicします。
+func (r *Registry) Register(rules ...*Rule) {
+	for _, rule := range rules {
+		if rule.ID == "" || rule.Check == nil {
+			panic(fmt.Sprintf("achievement: rule %q must have an id and a check", rule.ID))
+		}
+		if _, dup := r.byID[rule.ID]; dup {
+			panic(fmt.Sprintf("achievement: duplicate rule id %q", rule.ID))
+		}
+		r.rules = append(r.rules, rule)
+		r.byID[rule.ID] = rule
+	}
+}
+
+// Get はIDで指定したルールを返します。
+func (r *Registry) Get(id string) (*Rule, bool) {
+	rule, ok := r.byID[id]
+	return rule, ok
+}
+
+// Rules は登録順のルールの一覧を返します。
+func (r *Registry) Rules() []*Rule {
+	return append([]*Rule(nil), r.rules...)
+}
+
+// Evaluate はイベントを判定対象とするルールのうち、unlockedに含まれず条件を満たしたものを返します。
+func (r *Registry) Evaluate(ctx context.Context, in *Input, unlocked map[string]bool) ([]*Rule, error) {
+	var res []*Rule
+	for _, rule := range r.rules {
+		if unlocked[rule.ID] || !rule.Handles(in.Event) {
+			continue
+		}
+		ok, err := rule.Check(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("achievement %q: %w", rule.ID, err)
+		}
+		if ok {
+			res = append(res, rule)
+		}
+	}
+	return res, nil
+}
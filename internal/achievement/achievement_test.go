@@ -0,0 +1,146 @@
+package achievement
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stubRule はcheckの結果を返し、呼び出された回数を数えるルールを作成します。
+func stubRule(id string, result bool, err error, calls *int, events ...Event) *Rule {
+	return &Rule{
+		ID:     id,
+		Events: events,
+		Check: func(ctx context.Context, in *Input) (bool, error) {
+			*calls++
+			return result, err
+		},
+	}
+}
+
+func ruleIDs(rules []*Rule) []string {
+	ids := make([]string, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+func TestRegistryRegister(t *testing.T) {
+	var calls int
+	tests := []struct {
+		name  string
+		rules []*Rule
+	}{
+		{name: "duplicate id", rules: []*Rule{stubRule("a", true, nil, &calls, EventSolve), stubRule("a", true, nil, &calls, EventSolve)}},
+		{name: "empty id", rules: []*Rule{stubRule("", true, nil, &calls, EventSolve)}},
+		{name: "nil check", rules: []*Rule{{ID: "a", Events: []Event{EventSolve}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register did not panic")
+				}
+			}()
+			NewRegistry().Register(tt.rules...)
+		})
+	}
+}
+
+func TestRegistryGetAndRules(t *testing.T) {
+	var calls int
+	r := NewRegistry()
+	r.Register(stubRule("b", true, nil, &calls, EventSolve), stubRule("a", true, nil, &calls, EventPublish))
+
+	if got := ruleIDs(r.Rules()); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("Rules = %v, want registration order [b a]", got)
+	}
+	if rule, ok := r.Get("a"); !ok || rule.ID != "a" {
+		t.Errorf("Get(a) = %v, %v", rule, ok)
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Error("Get(missing) found a rule")
+	}
+
+	// Rulesの戻り値を変更してもRegistryには影響しない
+	rules := r.Rules()
+	rules[0] = nil
+	if r.Rules()[0] == nil {
+		t.Error("Rules returned the internal slice")
+	}
+}
+
+func TestRegistryEvaluate(t *testing.T) {
+	checkErr := errors.New("check failed")
+
+	tests := []struct {
+		name      string
+		event     Event
+		unlocked  map[string]bool
+		failing   bool
+		want      []string
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "matching rules that pass", event: EventSolve, want: []string{"solve_pass"}, wantCalls: 2},
+		{name: "other event", event: EventPublish, want: []string{"publish_pass"}, wantCalls: 1},
+		{name: "already unlocked is skipped", event: EventSolve, unlocked: map[string]bool{"solve_pass": true}, wantCalls: 1},
+		{name: "check error", event: EventSolve, failing: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			r := NewRegistry()
+			r.Register(
+				stubRule("solve_pass", true, nil, &calls, EventSolve),
+				stubRule("solve_fail", false, nil, &calls, EventSolve),
+				stubRule("publish_pass", true, nil, &calls, EventPublish),
+			)
+			if tt.failing {
+				r.Register(stubRule("solve_error", false, checkErr, &calls, EventSolve))
+			}
+
+			got, err := r.Evaluate(context.Background(), &Input{UserID: 1, Event: tt.event}, tt.unlocked)
+			if tt.wantErr {
+				if !errors.Is(err, checkErr) {
+					t.Fatalf("Evaluate error = %v, want wrapping %v", err, checkErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if ids := ruleIDs(got); len(ids) != len(tt.want) || (len(ids) > 0 && !reflect.DeepEqual(ids, tt.want)) {
+				t.Errorf("Evaluate = %v, want %v", ids, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Check called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	r := NewDefaultRegistry()
+	want := []string{
+		"first_solve", "solves_10", "solves_50",
+		"web_10", "crypto_10", "pwn_10", "rev_10",
+		"first_blood", "first_publish", "publish_5",
+	}
+	if got := ruleIDs(r.Rules()); !reflect.DeepEqual(got, want) {
+		t.Errorf("default rule ids = %v, want %v", got, want)
+	}
+
+	// 10問目を解いたユーザーはfirst_solveを解除済みならsolves_10とweb_10だけを解除する
+	stats := &fakeStats{solves: 10, categories: map[string]int{"web": 10}}
+	got, err := r.Evaluate(context.Background(), &Input{UserID: 1, Event: EventSolve, ChallengeID: 7, Stats: stats}, map[string]bool{"first_solve": true})
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if ids := ruleIDs(got); !reflect.DeepEqual(ids, []string{"solves_10", "web_10"}) {
+		t.Errorf("Evaluate = %v, want [solves_10 web_10]", ids)
+	}
+}
@@ -0,0 +1,79 @@
+package achievement
+
+import (
+	"context"
+	"strconv"
+)
+
+// NewDefaultRegistry は標準の実績を登録したRegistryを作成します。
+// 実績を追加する場合はここに登録し、メッセージカタログに表示名（achievements.names.<ID>）を追加してください。
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(
+		SolveCountRule("first_solve", 1),
+		SolveCountRule("solves_10", 10),
+		SolveCountRule("solves_50", 50),
+		CategorySolveRule("web_10", "web", 10),
+		CategorySolveRule("crypto_10", "crypto", 10),
+		CategorySolveRule("pwn_10", "pwn", 10),
+		CategorySolveRule("rev_10", "rev", 10),
+		FirstBloodRule("first_blood"),
+		PublishCountRule("first_publish", 1),
+		PublishCountRule("publish_5", 5),
+	)
+	return r
+}
+
+// SolveCountRule は公開問題をn問解くと解除される実績です。
+func SolveCountRule(id string, n int) *Rule {
+	return &Rule{
+		ID:             id,
+		DescriptionKey: "achievements.descriptions.solve_count",
+		Params:         map[string]string{"count": strconv.Itoa(n)},
+		Events:         []Event{EventSolve},
+		Check: func(ctx context.Context, in *Input) (bool, error) {
+			count, err := in.Stats.SolveCount(ctx)
+			return count >= n, err
+		},
+	}
+}
+
+// CategorySolveRule は指定したカテゴリーの公開問題をn問解くと解除される実績です。
+func CategorySolveRule(id, category string, n int) *Rule {
+	return &Rule{
+		ID:             id,
+		DescriptionKey: "achievements.descriptions.category_solve",
+		Params:         map[string]string{"category": category, "count": strconv.Itoa(n)},
+		Events:         []Event{EventSolve},
+		Check: func(ctx context.Context, in *Input) (bool, error) {
+			count, err := in.Stats.CategorySolveCount(ctx, category)
+			return count >= n, err
+		},
+	}
+}
+
+// FirstBloodRule は問題を作成者以外で最初に解くと解除される実績です。
+func FirstBloodRule(id string) *Rule {
+	return &Rule{
+		ID:             id,
+		DescriptionKey: "achievements.descriptions.first_blood",
+		Events:         []Event{EventSolve},
+		Check: func(ctx context.Context, in *Input) (bool, error) {
+			return in.Stats.IsFirstBlood(ctx, in.ChallengeID)
+		},
+	}
+}
+
+// PublishCountRule は問題をn問公開すると解除される実績です。
+func PublishCountRule(id string, n int) *Rule {
+	return &Rule{
+		ID:             id,
+		DescriptionKey: "achievements.descriptions.publish_count",
+		Params:         map[string]string{"count": strconv.Itoa(n)},
+		Events:         []Event{EventPublish},
+		Check: func(ctx context.Context, in *Input) (bool, error) {
+			count, err := in.Stats.PublishedCount(ctx)
+			return count >= n, err
+		},
+	}
+}
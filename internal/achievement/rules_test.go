@@ -0,0 +1,147 @@
+package achievement
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+)
+
+// fakeStats は固定の成績を返すStatsです。errを設定するとすべてのメソッドがそのエラーを返します。
+type fakeStats struct {
+	solves     int
+	categories map[string]int
+	firstBlood map[uint]bool
+	published  int
+	err        error
+}
+
+func (s *fakeStats) SolveCount(ctx context.Context) (int, error) {
+	return s.solves, s.err
+}
+
+func (s *fakeStats) CategorySolveCount(ctx context.Context, category string) (int, error) {
+	for name, count := range s.categories {
+		if strings.EqualFold(name, category) {
+			return count, s.err
+		}
+	}
+	return 0, s.err
+}
+
+func (s *fakeStats) IsFirstBlood(ctx context.Context, challengeID uint) (bool, error) {
+	return s.firstBlood[challengeID], s.err
+}
+
+func (s *fakeStats) PublishedCount(ctx context.Context) (int, error) {
+	return s.published, s.err
+}
+
+func TestRules(t *testing.T) {
+	statsErr := errors.New("stats unavailable")
+
+	tests := []struct {
+		name        string
+		rule        *Rule
+		challengeID uint
+		stats       *fakeStats
+		want        bool
+		wantErr     error
+	}{
+		{name: "solve count below threshold", rule: SolveCountRule("solves_10", 10), stats: &fakeStats{solves: 9}},
+		{name: "solve count at threshold", rule: SolveCountRule("solves_10", 10), stats: &fakeStats{solves: 10}, want: true},
+		{name: "solve count above threshold", rule: SolveCountRule("solves_10", 10), stats: &fakeStats{solves: 11}, want: true},
+		{name: "solve count error", rule: SolveCountRule("solves_10", 10), stats: &fakeStats{err: statsErr}, wantErr: statsErr},
+		{
+			name:  "category below threshold",
+			rule:  CategorySolveRule("web_10", "web", 10),
+			stats: &fakeStats{categories: map[string]int{"web": 9, "crypto": 20}},
+		},
+		{
+			name:  "category at threshold ignoring case",
+			rule:  CategorySolveRule("web_10", "web", 10),
+			stats: &fakeStats{categories: map[string]int{"Web": 10}},
+			want:  true,
+		},
+		{
+			name:  "other category does not count",
+			rule:  CategorySolveRule("web_10", "web", 10),
+			stats: &fakeStats{solves: 30, categories: map[string]int{"crypto": 30}},
+		},
+		{name: "category error", rule: CategorySolveRule("web_10", "web", 10), stats: &fakeStats{err: statsErr}, wantErr: statsErr},
+		{
+			name:        "first blood",
+			rule:        FirstBloodRule("first_blood"),
+			challengeID: 3,
+			stats:       &fakeStats{firstBlood: map[uint]bool{3: true}},
+			want:        true,
+		},
+		{
+			name:        "not first blood",
+			rule:        FirstBloodRule("first_blood"),
+			challengeID: 4,
+			stats:       &fakeStats{firstBlood: map[uint]bool{3: true}},
+		},
+		{name: "first blood error", rule: FirstBloodRule("first_blood"), challengeID: 3, stats: &fakeStats{err: statsErr}, wantErr: statsErr},
+		{name: "publish count below threshold", rule: PublishCountRule("publish_5", 5), stats: &fakeStats{published: 4}},
+		{name: "publish count at threshold", rule: PublishCountRule("publish_5", 5), stats: &fakeStats{published: 5}, want: true},
+		{name: "publish count error", rule: PublishCountRule("publish_5", 5), stats: &fakeStats{err: statsErr}, wantErr: statsErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Check(context.Background(), &Input{UserID: 1, ChallengeID: tt.challengeID, Stats: tt.stats})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Check = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEvents(t *testing.T) {
+	tests := []struct {
+		rule  *Rule
+		event Event
+	}{
+		{SolveCountRule("first_solve", 1), EventSolve},
+		{CategorySolveRule("web_10", "web", 10), EventSolve},
+		{FirstBloodRule("first_blood"), EventSolve},
+		{PublishCountRule("first_publish", 1), EventPublish},
+	}
+	for _, tt := range tests {
+		if len(tt.rule.Events) != 1 || !tt.rule.Handles(tt.event) {
+			t.Errorf("%s: Events = %v, want [%s]", tt.rule.ID, tt.rule.Events, tt.event)
+		}
+	}
+}
+
+func TestDefaultRegistryMessages(t *testing.T) {
+	rules := NewDefaultRegistry().Rules()
+	if len(rules) == 0 {
+		t.Fatal("NewDefaultRegistry registered no rules")
+	}
+	for _, rule := range rules {
+		for _, locale := range i18n.Supported {
+			name := i18n.T(locale, rule.NameKey(), nil)
+			description := i18n.T(locale, rule.DescriptionKey, rule.Params)
+			if name == rule.NameKey() || description == rule.DescriptionKey {
+				t.Errorf("%s (%s): missing catalog message: name=%q description=%q", rule.ID, locale, name, description)
+			}
+			if strings.ContainsAny(description, "{}") {
+				t.Errorf("%s (%s): unreplaced parameter in description %q", rule.ID, locale, description)
+			}
+		}
+	}
+
+	if got, want := i18n.T(i18n.Japanese, "achievements.descriptions.category_solve", CategorySolveRule("web_10", "web", 10).Params), "webの問題を10問解く"; got != want {
+		t.Errorf("ja category description = %q, want %q", got, want)
+	}
+	if got, want := i18n.T(i18n.English, "achievements.descriptions.category_solve", CategorySolveRule("web_10", "web", 10).Params), "Solve 10 web challenge(s)"; got != want {
+		t.Errorf("en category description = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AchievementHandler struct {
+	achievementService *service.AchievementService
+}
+
+func NewAchievementHandler(achievementService *service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+// ListAchievements godoc
+// @Summary      実績の一覧
+// @Description  解除できるすべての実績（バッジ）と解除条件をAccept-Languageの言語で返します
+// @Tags         user
+// @Produce      json
+// @Success      200  {array}  dtos.AchievementDTO
+// @Router       /api/public/achievements [get]
+func (h *AchievementHandler) ListAchievements(c *gin.Context) {
+	c.JSON(http.StatusOK, h.achievementService.Definitions(c.Request.Context()))
+}
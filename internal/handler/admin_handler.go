@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultUserListLimit = 20
+	maxUserListLimit     = 100
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// ListUsers godoc
+// @Summary      ユーザー一覧取得（管理者）
+// @Description  ユーザー名・メールアドレスの部分一致でユーザーを検索します
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        q      query  string  false  "検索キーワード"
+// @Param        page   query  int     false  "ページ番号"  default(1)
+// @Param        limit  query  int     false  "1ページあたりの件数"  default(20)
+// @Success      200    {object}  dtos.UserListResponse
+// @Failure      401    {object}  ProblemDetails
+// @Failure      403    {object}  ProblemDetails
+// @Failure      500    {object}  ProblemDetails
+// @Router       /api/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultUserListLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultUserListLimit
+	}
+	if limit > maxUserListLimit {
+		limit = maxUserListLimit
+	}
+
+	res, err := h.adminService.ListUsers(c.Request.Context(), c.Query("q"), page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetUser godoc
+// @Summary      ユーザー詳細取得（管理者）
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {object}  dtos.AdminUserDTO
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUserChallenges godoc
+// @Summary      ユーザーの作成問題一覧（管理者）
+// @Description  非公開の問題も含めて、指定ユーザーが作成した問題を返します
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {array}   models.Challenge
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/challenges [get]
+func (h *AdminHandler) GetUserChallenges(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	challenges, err := h.adminService.GetUserChallenges(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenges)
+}
+
+// GetUserSubmissions godoc
+// @Summary      ユーザーの提出履歴（管理者）
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {array}   dtos.SubmissionDTO
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/submissions [get]
+func (h *AdminHandler) GetUserSubmissions(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	submissions, err := h.adminService.GetUserSubmissions(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// BanUser godoc
+// @Summary      ユーザーをBAN（管理者）
+// @Description  BANされたユーザーはログイン・トークン更新・フラグ提出ができなくなります
+// @Tags         admin
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        userId  path  int                  true   "User ID"
+// @Param        body    body  dtos.BanUserRequest  false  "BAN理由"
+// @Success      200     {object}  MessageResponse
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/ban [post]
+func (h *AdminHandler) BanUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.BanUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, invalidRequest(err))
+			return
+		}
+	}
+
+	adminID, _ := token.GetUserID(c)
+	if err := h.adminService.BanUser(c.Request.Context(), adminID, userID, req.Reason); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.user_banned")
+}
+
+// UnbanUser godoc
+// @Summary      ユーザーのBAN解除（管理者）
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {object}  MessageResponse
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/unban [post]
+func (h *AdminHandler) UnbanUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	adminID, _ := token.GetUserID(c)
+	if err := h.adminService.UnbanUser(c.Request.Context(), adminID, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.user_unbanned")
+}
+
+// ForcePasswordReset godoc
+// @Summary      パスワード再設定の強制（管理者）
+// @Description  ユーザーはパスワードを再設定するまでパスワードでログインできなくなります
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {object}  MessageResponse
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	adminID, _ := token.GetUserID(c)
+	if err := h.adminService.ForcePasswordReset(c.Request.Context(), adminID, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.password_reset_forced")
+}
+
+// SetUserRole godoc
+// @Summary      ユーザーのロール変更（管理者）
+// @Description  ロールを変更したユーザーのセッションはすべて失効し、新しいロールは次回のログインから反映されます。自分自身のロールは変更できません
+// @Tags         admin
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        userId  path  int                      true  "User ID"
+// @Param        body    body  dtos.SetUserRoleRequest  true  "ロール（user または admin）"
+// @Success      200     {object}  MessageResponse
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId}/role [put]
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	adminID, _ := token.GetUserID(c)
+	if err := h.adminService.SetUserRole(c.Request.Context(), adminID, userID, req.Role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.user_role_updated")
+}
+
+// DeleteUser godoc
+// @Summary      ユーザー削除（管理者）
+// @Description  ユーザーと、そのユーザーの問題・提出履歴・OAuthアカウントを削除します
+// @Tags         admin
+// @Security     bearer
+// @Produce      json
+// @Param        userId  path  int  true  "User ID"
+// @Success      200     {object}  MessageResponse
+// @Failure      400     {object}  ProblemDetails
+// @Failure      401     {object}  ProblemDetails
+// @Failure      403     {object}  ProblemDetails
+// @Failure      404     {object}  ProblemDetails
+// @Failure      500     {object}  ProblemDetails
+// @Router       /api/admin/users/{userId} [delete]
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	adminID, _ := token.GetUserID(c)
+	if err := h.adminService.DeleteUser(c.Request.Context(), adminID, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.user_deleted")
+}
+
+// parseUserIDParam はパスパラメータのユーザーIDを解析します。失敗した場合は400を返します。
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("userId", err))
+		return 0, false
+	}
+	return uint(userID), true
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+	validate        *validator.Validate
+}
+
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+		validate:        validation.Default(),
+	}
+}
+
+// CreateAPIToken godoc
+// @Summary      パーソナルアクセストークンの発行
+// @Description  CLIや自動化用のスコープ付きトークンを発行します。トークン本体はこのレスポンスでのみ表示されます
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dtos.CreateAPITokenRequest  true  "トークン情報"
+// @Success      201   {object}  dtos.APITokenCreatedResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/tokens [post]
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	var req dtos.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	res, err := h.apiTokenService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// ListAPITokens godoc
+// @Summary      パーソナルアクセストークン一覧
+// @Description  自分の有効なパーソナルアクセストークンの一覧を返します
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {array}   dtos.APITokenDTO
+// @Failure      401  {object}  ProblemDetails
+// @Failure      403  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/tokens [get]
+func (h *APITokenHandler) ListAPITokens(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeAPIToken godoc
+// @Summary      パーソナルアクセストークンの失効
+// @Description  指定したパーソナルアクセストークンを失効させます
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Param        tokenId  path  int  true  "Token ID"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ProblemDetails
+// @Failure      401      {object}  ProblemDetails
+// @Failure      403      {object}  ProblemDetails
+// @Failure      404      {object}  ProblemDetails
+// @Failure      500      {object}  ProblemDetails
+// @Router       /api/me/tokens/{tokenId} [delete]
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("tokenId", err))
+		return
+	}
+
+	if err := h.apiTokenService.Revoke(c.Request.Context(), userID, uint(tokenID)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.api_token_revoked")
+}
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,26 +10,35 @@ import (
 
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
 )
 
 // Swag用のレスポンス型定義
-type ErrorResponse struct {
-	Error string `json:"error" example:"error message"`
-}
-
 type MessageResponse struct {
-	Message string `json:"message" example:"success message"`
+	Message    string `json:"message" example:"Signed out successfully."` // リクエストの言語のメッセージ
+	MessageKey string `json:"message_key" example:"messages.logout_successful"`
 }
 
 type TokenResponse struct {
-	Message      string `json:"message" example:"login successful"`
+	Message      string `json:"message" example:"Signed in successfully."`
+	MessageKey   string `json:"message_key" example:"messages.login_successful"`
 	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	ExpiresIn    int64  `json:"expires_in" example:"3600"`
 }
 
+type MFARequiredResponse struct {
+	Message     string `json:"message" example:"Enter your two-factor authentication code."`
+	MessageKey  string `json:"message_key" example:"messages.mfa_required"`
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn   int64  `json:"expires_in" example:"300"`
+}
+
 type OAuthResponse struct {
-	Message      string `json:"message" example:"oauth authentication successful"`
+	Message      string `json:"message" example:"Signed in with OAuth successfully."`
+	MessageKey   string `json:"message_key" example:"messages.oauth_login_successful"`
 	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	ExpiresIn    int64  `json:"expires_in" example:"3600"`
@@ -48,7 +57,7 @@ type AuthHandler struct {
 func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		validate:    validator.New(),
+		validate:    validation.Default(),
 	}
 }
 
@@ -64,36 +73,41 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required" example:"password123"`
 }
 
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" validate:"required" example:"123456"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 }
 
 // Register godoc
 // @Summary      ユーザー登録
-// @Description  新規ユーザーを登録します
+// @Description  新規ユーザーを登録し、メールアドレスの確認メールを送信します
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        body  body  RegisterRequest  true  "登録情報"
 // @Success      201   {object}  MessageResponse
-// @Failure      400   {object}  ErrorResponse
-// @Failure      500   {object}  ErrorResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
 // @Router       /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, invalidRequest(err))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, validationFailed(err))
 		return
 	}
 
 	hashedPwd, err := h.authService.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hashing failed"})
+		respondError(c, err)
 		return
 	}
 
@@ -101,61 +115,120 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: hashedPwd,
+		Role:         models.RoleUser,
 		CreatedAt:    time.Now(),
 	}
 
 	if err := h.authService.RegisterUser(c.Request.Context(), user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "user registered successfully"})
+	respondMessage(c, http.StatusCreated, "messages.user_registered")
 }
 
 // Login godoc
 // @Summary      ユーザーログイン
-// @Description  メールとパスワードでログインします
+// @Description  メールとパスワードでログインします。二要素認証が有効な場合はトークンの代わりにMFARequiredResponse（mfa_token）を返すので、/auth/login/mfaでコードを送信してください
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        body  body  LoginRequest  true  "ログイン情報"
 // @Success      200   {object}  TokenResponse
-// @Failure      400   {object}  ErrorResponse
-// @Failure      401   {object}  ErrorResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, invalidRequest(err))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, validationFailed(err))
 		return
 	}
 
-	tokenPair, err := h.authService.Login(context.Background(), req.Email, req.Password)
+	result, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed"})
+		respondError(c, err)
+		return
+	}
+
+	if result.MFARequired {
+		respondMFARequired(c, result)
+		return
+	}
+
+	respondLoginSuccess(c, result)
+}
+
+// LoginMFA godoc
+// @Summary      二要素認証ログイン
+// @Description  ログイン時に発行された二要素認証待ちトークンとTOTPコード（またはリカバリーコード）を検証し、トークンを発行します
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  MFALoginRequest  true  "二要素認証情報"
+// @Success      200   {object}  TokenResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      429   {object}  ProblemDetails
+// @Router       /auth/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
 		return
 	}
 
-	var user *models.User
-	user, err = h.authService.GetUserByEmail(c.Request.Context(), req.Email)
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	result, err := h.authService.CompleteMFALogin(c.Request.Context(), req.MFAToken, req.Code, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed"})
+		switch {
+		case errors.Is(err, service.ErrInvalidTOTPCode):
+			// ログイン時のコードの誤りは認証の失敗として扱う
+			err = apperror.Unauthorized("invalid_totp_code", "invalid code").Wrap(err)
+		case errors.Is(err, service.ErrUserNotFound):
+			err = apperror.Unauthorized("authentication_failed", "authentication failed").Wrap(err)
+		}
+		respondError(c, err)
 		return
 	}
 
+	respondLoginSuccess(c, result)
+}
+
+// respondMFARequired は二要素認証待ちトークンのレスポンスを返します。
+func respondMFARequired(c *gin.Context, result *service.LoginResult) {
+	c.JSON(http.StatusOK, MFARequiredResponse{
+		Message:     localize(c, "messages.mfa_required"),
+		MessageKey:  "messages.mfa_required",
+		MFARequired: true,
+		MFAToken:    result.MFAToken,
+		ExpiresIn:   result.MFAExpiresIn,
+	})
+}
+
+// respondLoginSuccess はログイン成功時のレスポンスを返します。
+func respondLoginSuccess(c *gin.Context, result *service.LoginResult) {
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "login successful",
-		"access_token":  tokenPair.AccessToken,
-		"refresh_token": tokenPair.RefreshToken,
-		"expires_in":    tokenPair.ExpiresIn,
+		"message":       localize(c, "messages.login_successful"),
+		"message_key":   "messages.login_successful",
+		"access_token":  result.TokenPair.AccessToken,
+		"refresh_token": result.TokenPair.RefreshToken,
+		"expires_in":    result.TokenPair.ExpiresIn,
 		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
+			"id":       result.User.ID,
+			"username": result.User.Username,
+			"role":     result.User.Role,
 		},
 	})
 }
@@ -168,24 +241,25 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Produce      json
 // @Param        body  body  RefreshTokenRequest  true  "リフレッシュトークン"
 // @Success      200   {object}  TokenResponse
-// @Failure      400   {object}  ErrorResponse
-// @Failure      401   {object}  ErrorResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
 // @Router       /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, invalidRequest(err))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, validationFailed(err))
 		return
 	}
 
-	tokenPair, err := h.authService.RefreshToken(req.RefreshToken)
+	tokenPair, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		respondError(c, err)
 		return
 	}
 
@@ -198,29 +272,72 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary      ログアウト
-// @Description  ユーザーログアウトします
+// @Description  リフレッシュトークンが属するセッションを失効させます
 // @Tags         auth
 // @Accept       json
 // @Produce      json
+// @Param        body  body  RefreshTokenRequest  true  "リフレッシュトークン"
 // @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
 // @Router       /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// クライアント側でトークンを削除することを前提とする
-	// 必要に応じてブラックリストにトークンを追加する処理を追加可能
-	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.logout_successful")
 }
 
 // MeResponse swag用
 // @Description 自分のユーザー情報
 // @Tags user
 // @Success 200 {object} MeResponse
-// @Failure 401 {object} ErrorResponse
+// @Failure 401 {object} ProblemDetails
 // @Router /api/me [get]
 type MeResponse struct {
-	UserID    uint      `json:"user_id" example:"1"`
-	Username  string    `json:"username" example:"testuser"`
-	Email     string    `json:"email" example:"test@example.com"`
-	CreatedAt time.Time `json:"created_at" example:"2024-08-01T12:34:56Z"`
+	UserID        uint      `json:"user_id" example:"1"`
+	Username      string    `json:"username" example:"testuser"`
+	Email         string    `json:"email" example:"test@example.com"`
+	Role          string    `json:"role" example:"user"`
+	EmailVerified bool      `json:"email_verified" example:"true"`
+	TOTPEnabled   bool      `json:"totp_enabled" example:"false"`
+	DisplayName   string    `json:"display_name" example:"Test User"`
+	Bio           string    `json:"bio" example:"Web/Crypto好きです"`
+	AvatarURL     string    `json:"avatar_url" example:"https://example.com/avatar.png"`
+	PendingEmail  string    `json:"pending_email,omitempty" example:"new@example.com"` // 確認待ちの新しいメールアドレス
+	HideSolves    bool      `json:"hide_solves" example:"false"`
+	CreatedAt     time.Time `json:"created_at" example:"2024-08-01T12:34:56Z"`
+}
+
+func newMeResponse(user *models.User) MeResponse {
+	return MeResponse{
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		TOTPEnabled:   user.TOTPEnabled,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarURL:     user.AvatarURL,
+		PendingEmail:  user.PendingEmail,
+		HideSolves:    user.HideSolves,
+		CreatedAt:     user.CreatedAt,
+	}
 }
 
 // Me godoc
@@ -230,28 +347,27 @@ type MeResponse struct {
 // @Security     bearer
 // @Produce      json
 // @Success      200   {object}  MeResponse
-// @Failure      401   {object}  ErrorResponse
+// @Failure      401   {object}  ProblemDetails
 // @Router       /api/me [get]
 func (h *AuthHandler) Me(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		respondError(c, errUnauthorized)
 		return
 	}
 	id, ok := userID.(uint)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid user id"})
+		respondError(c, errUnauthorized)
 		return
 	}
 	user, err := h.authService.GetUserByID(c.Request.Context(), id)
-	if err != nil || user == nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "user not found"})
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, MeResponse{
-		UserID:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	})
+	if user == nil {
+		respondError(c, errUnauthorized)
+		return
+	}
+	c.JSON(http.StatusOK, newMeResponse(user))
 }
@@ -1,23 +1,25 @@
 package handler
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
 	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 type ChallengeHandler struct {
-	service service.ChallengeService
+	service  service.ChallengeService
+	validate *validator.Validate
 }
 
 func NewChallengeHandler(service service.ChallengeService) *ChallengeHandler {
-	return &ChallengeHandler{service: service}
+	return &ChallengeHandler{service: service, validate: validation.Default()}
 }
 
 // @Summary 新しい問題を作成
@@ -28,21 +30,26 @@ func NewChallengeHandler(service service.ChallengeService) *ChallengeHandler {
 // @Security BearerAuth
 // @Param challenge body dtos.CreateChallengeRequest true "問題作成情報"
 // @Success 201 {object} dtos.ChallengeCreateResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 401 {object} ProblemDetails
+// @Failure 403 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/challenges [post]
 func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
 	var req dtos.CreateChallengeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
 		return
 	}
 
 	// 認証されたユーザーのIDをコンテキストから取得
 	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
@@ -57,38 +64,40 @@ func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
 	}
 
 	// サービスを呼び出して問題を作成し、カテゴリー名を渡します
-	if err := h.service.CreateChallenge(context.Background(), challenge, req.Category); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create challenge: " + err.Error()})
+	if err := h.service.CreateChallenge(c.Request.Context(), challenge, req.Category); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Challenge created successfully",
-		"id":      challenge.ID,
+		"message":     localize(c, "messages.challenge_created"),
+		"message_key": "messages.challenge_created",
+		"id":          challenge.ID,
 	})
 }
 
-// @Summary ユーザーが作成した問題を取得
-// @Description 認証されたユーザーが作成した問題のリストを取得します
+// @Summary 自分が作成した問題を取得
+// @Description 認証されたユーザーが作成した問題（非公開を含む）のリストを取得します
 // @Tags challenges
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} models.Challenge
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /api/me/challenges [get]
-func (h *ChallengeHandler) CollectChallengesByUsername(c *gin.Context) {
-	// 認証されたユーザー名を取得
-	username, exists := token.GetUsername(c)
+// @Failure 401 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
+// @Router /api/challenges [get]
+func (h *ChallengeHandler) CollectMyChallenges(c *gin.Context) {
+	// 認証されたユーザーIDを取得
+	// アクセストークンのユーザー名は変更前の可能性があるため、ユーザーIDで取得する
+	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
-	// サービス層のCollectByUsernameを呼び出してチャレンジを取得
-	challenges, err := h.service.CollectByUsername(c.Request.Context(), username)
+	// サービス層のCollectByUserIDを呼び出してチャレンジを取得
+	challenges, err := h.service.CollectByUserID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get challenges: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -96,8 +105,29 @@ func (h *ChallengeHandler) CollectChallengesByUsername(c *gin.Context) {
 	c.JSON(http.StatusOK, challenges)
 }
 
+// @Summary ユーザーが作成した問題を取得
+// @Description パスで指定したユーザーが作成した公開中の問題のリストを取得します。ログインしている場合はis_solvedに自分が解いたかどうかが入ります
+// @Tags challenges
+// @Produce json
+// @Param username path string true "ユーザー名"
+// @Success 200 {array} dtos.ChallengePublicDTO
+// @Failure 404 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
+// @Router /api/public/users/{username}/challenges [get]
+func (h *ChallengeHandler) CollectChallengesByUsername(c *gin.Context) {
+	viewerID, _ := token.GetUserID(c)
+
+	challenges, err := h.service.CollectPublicByUsername(c.Request.Context(), c.Param("username"), viewerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenges)
+}
+
 // @Summary 問題を更新
-// @Description 既存の問題を更新します
+// @Description 既存の問題を更新します（管理者は他のユーザーの問題も更新・非公開化できます）
 // @Tags challenges
 // @Accept json
 // @Produce json
@@ -105,37 +135,43 @@ func (h *ChallengeHandler) CollectChallengesByUsername(c *gin.Context) {
 // @Param challengeId path int true "Challenge ID"
 // @Param challenge body dtos.UpdateChallengeRequest true "問題更新情報"
 // @Success 200 {object} MessageResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 401 {object} ProblemDetails
+// @Failure 403 {object} ProblemDetails
+// @Failure 404 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/challenges/{challengeId} [put]
 func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
 	challengeID, err := strconv.ParseUint(c.Param("challengeId"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge ID"})
+		respondError(c, invalidParam("challengeId", err))
 		return
 	}
 
 	var req dtos.UpdateChallengeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
 		return
 	}
 
 	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
-	if err := h.service.UpdateChallenge(c.Request.Context(), uint(challengeID), userID, &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update challenge: " + err.Error()})
+	role, _ := token.GetRole(c)
+
+	if err := h.service.UpdateChallenge(c.Request.Context(), uint(challengeID), userID, role, &req); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Challenge updated successfully"})
+	respondMessage(c, http.StatusOK, "messages.challenge_updated")
 }
 
 // @Summary 問題を削除
@@ -146,62 +182,64 @@ func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
 // @Security BearerAuth
 // @Param challengeId path int true "Challenge ID"
 // @Success 200 {object} MessageResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 401 {object} ProblemDetails
+// @Failure 403 {object} ProblemDetails
+// @Failure 404 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/challenges/{challengeId} [delete]
 func (h *ChallengeHandler) DeleteChallenge(c *gin.Context) {
 	challengeID, err := strconv.ParseUint(c.Param("challengeId"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge ID"})
+		respondError(c, invalidParam("challengeId", err))
 		return
 	}
 
 	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
 	if err := h.service.DeleteChallenge(c.Request.Context(), uint(challengeID), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete challenge: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Challenge deleted successfully"})
+	respondMessage(c, http.StatusOK, "messages.challenge_deleted")
 }
 
 // @Summary 問題詳細を取得
-// @Description 問題IDを指定して、問題の詳細を取得します（所有者のみフラグ表示）
+// @Description 問題IDを指定して、問題の詳細を取得します（所有者と管理者のみフラグ表示）
 // @Tags challenges
 // @Produce json
 // @Security BearerAuth
 // @Param challengeId path int true "Challenge ID"
 // @Success 200 {object} dtos.ChallengeDetailResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 401 {object} ProblemDetails
+// @Failure 403 {object} ProblemDetails
+// @Failure 404 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/challenges/{challengeId} [get]
 func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
 	challengeID, err := strconv.ParseUint(c.Param("challengeId"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge ID"})
+		respondError(c, invalidParam("challengeId", err))
 		return
 	}
 
 	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
-	challenge, err := h.service.GetChallengeByID(c.Request.Context(), uint(challengeID), userID)
+	role, _ := token.GetRole(c)
+
+	challenge, err := h.service.GetChallengeByID(c.Request.Context(), uint(challengeID), userID, role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get challenge: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -214,14 +252,14 @@ func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
 // @Produce json
 // @Param challengeId path int true "Challenge ID"
 // @Success 200 {object} dtos.ChallengePublicDTO
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 404 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/public/challenges/{challengeId} [get]
 func (h *ChallengeHandler) GetPublicChallenge(c *gin.Context) {
 	challengeID, err := strconv.ParseUint(c.Param("challengeId"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge ID"})
+		respondError(c, invalidParam("challengeId", err))
 		return
 	}
 
@@ -230,7 +268,7 @@ func (h *ChallengeHandler) GetPublicChallenge(c *gin.Context) {
 
 	challenge, err := h.service.GetPublicChallengeByID(c.Request.Context(), uint(challengeID), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get challenge: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -242,14 +280,14 @@ func (h *ChallengeHandler) GetPublicChallenge(c *gin.Context) {
 // @Tags public_challenges
 // @Produce json
 // @Success 200 {array} dtos.ChallengePublicDTO
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} ProblemDetails
 // @Router /api/public/challenges [get]
 func (h *ChallengeHandler) GetAllPublicChallenges(c *gin.Context) {
 	userID, _ := token.GetUserID(c)
 
 	challenges, err := h.service.GetAllPublicChallenges(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get challenges: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -265,34 +303,44 @@ func (h *ChallengeHandler) GetAllPublicChallenges(c *gin.Context) {
 // @Param challengeId path int true "Challenge ID"
 // @Param submission body dtos.SubmissionRequest true "提出情報"
 // @Success 200 {object} dtos.SubmissionResponse "提出結果"
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemDetails
+// @Failure 401 {object} ProblemDetails
+// @Failure 403 {object} ProblemDetails
+// @Failure 500 {object} ProblemDetails
 // @Router /api/challenges/{challengeId}/submit [post]
 func (h *ChallengeHandler) SubmitFlag(c *gin.Context) {
 	challengeID, err := strconv.ParseUint(c.Param("challengeId"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge ID"})
+		respondError(c, invalidParam("challengeId", err))
 		return
 	}
 
 	var req dtos.SubmissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
 		return
 	}
 
 	userID, exists := token.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, errUnauthorized)
 		return
 	}
 
-	correct, err := h.service.SubmitFlag(c.Request.Context(), uint(challengeID), userID, req.Flag)
+	res, err := h.service.SubmitFlag(c.Request.Context(), uint(challengeID), userID, req.Flag)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process submission: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Submission processed", "correct": correct})
+	res.MessageKey = "submission.incorrect"
+	if res.Correct {
+		res.MessageKey = "submission.correct"
+	}
+	res.Message = localize(c, res.MessageKey)
+	c.JSON(http.StatusOK, res)
 }
\ No newline at end of file
@@ -0,0 +1,11 @@
+package dtos
+
+import "time"
+
+// AchievementDTO は実績の情報です。実績の一覧では解除日時を含みません。
+type AchievementDTO struct {
+	ID          string     `json:"id" example:"first_blood"`
+	Name        string     `json:"name" example:"First Blood"`
+	Description string     `json:"description" example:"Be the first to solve a challenge"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dtos
+
+import "time"
+
+// CreateAPITokenRequest はパーソナルアクセストークン発行APIのリクエストです。
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100" example:"ci-upload"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required" example:"challenges:write,submit"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=0,max=365" example:"90"` // 0の場合は無期限
+}
+
+// APITokenDTO はパーソナルアクセストークンの情報です。トークン本体は含みません。
+type APITokenDTO struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// APITokenCreatedResponse はパーソナルアクセストークン発行APIのレスポンスです。トークン本体はこのレスポンスでのみ表示されます。
+type APITokenCreatedResponse struct {
+	APITokenDTO
+	Token string `json:"token" example:"ctfp_q3Xk..."`
+}
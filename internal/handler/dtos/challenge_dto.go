@@ -2,27 +2,30 @@ package dtos
 
 // CreateChallengeRequestは問題作成APIのリクエストボディを定義します。
 type CreateChallengeRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	Category    string `json:"category"` // カテゴリー名を文字列として受け取ります
-	Score       int    `json:"score" binding:"required"`
-	Flag        string `json:"flag" binding:"required"`
+	Title       string `json:"title" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=10000"`
+	Category    string `json:"category" validate:"max=50"` // カテゴリー名を文字列として受け取ります
+	Score       int    `json:"score" validate:"min=1,max=10000"`
+	Flag        string `json:"flag" validate:"required,max=256"`
 	IsPublic    bool   `json:"is_public"`
 }
 
 // UpdateChallengeRequest は問題更新APIのリクエストボディを定義します。
+// 省略したフィールドは変更しません。指定したフィールドは作成時と同じ条件で検証します。
 type UpdateChallengeRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Category    *string `json:"category,omitempty"`
-	Score       *int    `json:"score,omitempty"`
-	Flag        *string `json:"flag,omitempty"`
+	Title       *string `json:"title,omitempty" validate:"omitnil,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitnil,max=10000"`
+	Category    *string `json:"category,omitempty" validate:"omitnil,max=50"`
+	Score       *int    `json:"score,omitempty" validate:"omitnil,min=1,max=10000"`
+	Flag        *string `json:"flag,omitempty" validate:"omitnil,min=1,max=256"`
 	IsPublic    *bool   `json:"is_public,omitempty"`
 }
 
 // ChallengeCreateResponseは問題作成成功時のレスポンスです。
 type ChallengeCreateResponse struct {
-	Message string `json:"message"`
+	Message    string `json:"message"`
+	MessageKey string `json:"message_key" example:"messages.challenge_created"`
+	ID         uint   `json:"id"`
 }
 
 // ChallengeDetailResponse は問題詳細取得APIのレスポンスです。
@@ -36,17 +39,14 @@ type ChallengeDetailResponse struct {
 	IsPublic    bool    `json:"is_public"`
 }
 
-// ErrorResponseはエラー発生時のレスポンスです。
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 // SubmissionRequest はフラグ提出APIのリクエストボディを定義します。
 type SubmissionRequest struct {
-	Flag string `json:"flag" binding:"required"`
+	Flag string `json:"flag" validate:"required,max=256"`
 }
 
 type SubmissionResponse struct {
-	Message string `json:"message"`
-	Correct bool   `json:"correct"`
+	Message              string            `json:"message"`                                  // リクエストの言語の結果のメッセージ
+	MessageKey           string            `json:"message_key" example:"submission.correct"` // submission.correct または submission.incorrect
+	Correct              bool              `json:"correct"`
+	UnlockedAchievements []*AchievementDTO `json:"unlocked_achievements,omitempty"` // この提出で新しく解除した実績
 }
@@ -0,0 +1,58 @@
+package dtos
+
+import "time"
+
+// UpdateProfileRequest はプロフィール更新APIのリクエストボディです。
+// 省略した項目は変更しません。表示名・自己紹介・アイコン画像のURLは空文字列で削除できます。
+type UpdateProfileRequest struct {
+	Username    *string `json:"username" validate:"omitempty,min=3,max=20" example:"newname"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=50" example:"New Name"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500" example:"Web/Crypto好きです"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,url,max=500" example:"https://example.com/avatar.png"`
+	HideSolves  *bool   `json:"hide_solves" example:"false"` // 公開プロフィールで解いた問題を非表示にする
+}
+
+// PublicProfileResponse は公開プロフィールAPIのレスポンスです。
+// 本人以外が閲覧する場合、解いた問題を非表示にしているユーザーのsolvesとcategoriesは含まず、
+// total_score・solve_countは0、rankはnull、achievementsはフラグの正解で解除する実績を除いたものになります。
+type PublicProfileResponse struct {
+	Username           string                  `json:"username" example:"testuser"`
+	DisplayName        string                  `json:"display_name" example:"Test User"`
+	Bio                string                  `json:"bio" example:"Web/Crypto好きです"`
+	AvatarURL          string                  `json:"avatar_url" example:"https://example.com/avatar.png"`
+	JoinedAt           time.Time               `json:"joined_at"`
+	TotalScore         int                     `json:"total_score" example:"1200"`
+	Rank               *int                    `json:"rank" example:"3"` // スコアが0の場合はnull
+	SolveCount         int                     `json:"solve_count" example:"12"`
+	SolvesHidden       bool                    `json:"solves_hidden" example:"false"`
+	AuthoredChallenges []*AuthoredChallengeDTO `json:"authored_challenges"`
+	Achievements       []*AchievementDTO       `json:"achievements"`
+	Solves             []*SolveDTO             `json:"solves,omitempty"`
+	Categories         []*CategoryStatDTO      `json:"categories,omitempty"`
+}
+
+// AuthoredChallengeDTO はユーザーが作成した公開問題です。
+type AuthoredChallengeDTO struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SolveDTO はユーザーが解いた問題です。
+type SolveDTO struct {
+	ChallengeID uint      `json:"challenge_id"`
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	Score       int       `json:"score"`
+	SolvedAt    time.Time `json:"solved_at"`
+}
+
+// CategoryStatDTO はカテゴリーごとの解いた問題数とスコアです。
+type CategoryStatDTO struct {
+	Category string `json:"category" example:"web"`
+	Solved   int    `json:"solved" example:"4"`
+	Total    int64  `json:"total" example:"10"` // カテゴリーの公開問題数
+	Score    int    `json:"score" example:"400"`
+}
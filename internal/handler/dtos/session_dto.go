@@ -0,0 +1,14 @@
+package dtos
+
+import "time"
+
+// SessionDTO はログイン中のセッション情報です。
+type SessionDTO struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // リクエストに使用したアクセストークンのセッションかどうか
+}
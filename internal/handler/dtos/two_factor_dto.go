@@ -0,0 +1,12 @@
+package dtos
+
+// TOTPEnrollResponse は二要素認証の登録開始APIのレスポンスです。
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/CTFForge:testuser?issuer=CTFForge&secret=JBSWY3DPEHPK3PXP"` // QRコード用
+}
+
+// RecoveryCodesResponse はリカバリーコードを返すAPIのレスポンスです。コードはこのレスポンスでのみ表示されます。
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,64 @@
+package dtos
+
+import "time"
+
+// AdminUserDTO は管理者向けのユーザー情報です。
+type AdminUserDTO struct {
+	ID                    uint       `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	BanReason             string     `json:"ban_reason,omitempty"`
+	BannedAt              *time.Time `json:"banned_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	EmailVerified         bool       `json:"email_verified"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// UserListResponse はユーザー一覧APIのレスポンスです。
+type UserListResponse struct {
+	Users []*AdminUserDTO `json:"users"`
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+}
+
+// SubmissionDTO は提出履歴の1件分です。
+type SubmissionDTO struct {
+	ID             uint      `json:"id"`
+	ChallengeID    uint      `json:"challenge_id"`
+	ChallengeTitle string    `json:"challenge_title"`
+	IsCorrect      bool      `json:"is_correct"`
+	SubmittedAt    time.Time `json:"submitted_at"`
+}
+
+// BanUserRequest はユーザーBAN APIのリクエストボディを定義します。
+type BanUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SetUserRoleRequest はユーザーのロール変更APIのリクエストボディを定義します。
+type SetUserRoleRequest struct {
+	Role string `json:"role" example:"admin"` // user または admin
+}
+
+// IdentityDTO は連携済みのOAuthプロバイダーの情報です。
+type IdentityDTO struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider" example:"github"`
+	ProviderUserID string    `json:"provider_user_id"`
+	LinkedAt       time.Time `json:"linked_at"`
+}
+
+// IdentitiesResponse はログイン方法の一覧です。
+type IdentitiesResponse struct {
+	HasPassword bool           `json:"has_password"` // メールアドレスとパスワードでログインできるか
+	Identities  []*IdentityDTO `json:"identities"`
+}
+
+// OAuthLinkResponse はOAuthプロバイダー連携開始APIのレスポンスです。
+type OAuthLinkResponse struct {
+	AuthorizeURL string `json:"authorize_url" example:"/auth/github?link=1"`
+	ExpiresIn    int64  `json:"expires_in" example:"300"`
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+)
+
+type EmailVerificationHandler struct {
+	emailVerifyService *service.EmailVerificationService
+	validate           *validator.Validate
+}
+
+func NewEmailVerificationHandler(emailVerifyService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerifyService: emailVerifyService,
+		validate:           validation.Default(),
+	}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// VerifyEmail godoc
+// @Summary      メールアドレスの確認
+// @Description  確認メールのリンクに含まれるトークンを検証し、メールアドレスを確認済みにします
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  VerifyEmailRequest  true  "確認トークン"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/email/verify [post]
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.emailVerifyService.Verify(c.Request.Context(), req.Token); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.email_verified")
+}
+
+// ConfirmEmailChange godoc
+// @Summary      メールアドレス変更の確定
+// @Description  新しいメールアドレスに届いた確認リンクのトークンを検証し、メールアドレスを変更します。以前のメールアドレスには変更の通知が送信されます
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  VerifyEmailRequest  true  "確認トークン"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/email/change/confirm [post]
+func (h *EmailVerificationHandler) ConfirmEmailChange(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.emailVerifyService.ConfirmEmailChange(c.Request.Context(), req.Token); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.email_changed")
+}
+
+// ResendVerification godoc
+// @Summary      確認メールの再送
+// @Description  メールアドレスの確認メールを再送します。前回の送信から一定時間内は再送できません
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {object}  MessageResponse
+// @Failure      401  {object}  ProblemDetails
+// @Failure      409  {object}  ProblemDetails
+// @Failure      429  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/email/verification [post]
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if err := h.emailVerifyService.ResendVerification(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.verification_sent")
+}
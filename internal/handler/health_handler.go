@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+type LivenessResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// Liveness godoc
+// @Summary      ライブネスチェック
+// @Description  プロセスが応答できるかどうかを返します。依存するサービスの状態は確認しません
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  LivenessResponse
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{Status: health.StatusOK})
+}
+
+// Readiness godoc
+// @Summary      レディネスチェック
+// @Description  データベースなどの登録されたコンポーネントを確認し、コンポーネントごとの状態を返します。必須のコンポーネントが1つでも失敗した場合は503を返します。必須でないコンポーネントの失敗はstatusがdegradedになります
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+)
+
+// localize はkeyのメッセージをリクエストの言語で返します。
+func localize(c *gin.Context, key string) string {
+	return i18n.T(i18n.FromContext(c.Request.Context()), key, nil)
+}
+
+// respondMessage はメッセージとそのキーだけのレスポンス（MessageResponse）を返します。
+func respondMessage(c *gin.Context, status int, key string) {
+	c.JSON(status, MessageResponse{Message: localize(c, key), MessageKey: key})
+}
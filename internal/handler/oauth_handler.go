@@ -2,53 +2,152 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"net/url"
+	"strconv"
 
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/oauth"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
 	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/markbates/goth/gothic"
 )
 
+// 認証開始からコールバックまで状態を保持するCookie
+// gothicのセッションはプロバイダーごとの状態で上書きされるため、別のCookieを使用する
+const (
+	oauthLinkCookie     = "oauth_link_token" // ログイン中のアカウントへの連携
+	oauthRedirectCookie = "oauth_redirect"   // ログイン後のリダイレクト先とPKCEのcode_challenge
+	oauthCookieMaxAge   = 10 * 60            // 秒
+)
+
+var (
+	errInvalidProvider           = apperror.Validation("invalid_provider", "invalid provider")
+	errInvalidLinkToken          = apperror.Unauthorized("invalid_link_token", "invalid link token")
+	errOAuthAuthenticationFailed = apperror.Unauthorized("oauth_authentication_failed", "oauth authentication failed")
+	errOAuthFailed               = apperror.New(apperror.KindInternal, "oauth_failed", "failed to process oauth user")
+)
+
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers" example:"github,google,keycloak"`
+}
+
+type OAuthExchangeRequest struct {
+	Code         string `json:"code" validate:"required" example:"q3Xk9a..."`
+	RedirectURI  string `json:"redirect_uri" validate:"required" example:"http://localhost:5173/auth/callback"`
+	CodeVerifier string `json:"code_verifier" example:"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"` // PKCEを使用した場合は必須
+}
+
+type OAuthSignupRequiredResponse struct {
+	Message           string `json:"message" example:"Choose a username to finish signing up."`
+	MessageKey        string `json:"message_key" example:"messages.signup_required"`
+	SignupRequired    bool   `json:"signup_required" example:"true"`
+	SignupToken       string `json:"signup_token" example:"q3Xk9a..."`
+	SuggestedUsername string `json:"suggested_username" example:"jose_garcia"`
+	ExpiresIn         int64  `json:"expires_in" example:"900"`
+}
+
+type OAuthSignupRequest struct {
+	SignupToken  string `json:"signup_token" validate:"required" example:"q3Xk9a..."`
+	Username     string `json:"username" example:"jose_garcia"`                                      // 省略時は提案されたユーザー名を使用
+	CodeVerifier string `json:"code_verifier" example:"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"` // PKCEを使用した場合は必須
+}
+
 type OAuthHandler struct {
 	oauthService *service.OAuthService
 	jwtManager   *token.JWTManager
+	validate     *validator.Validate
 }
 
 func NewOAuthHandler(oauthService *service.OAuthService, jwtManager *token.JWTManager) *OAuthHandler {
 	return &OAuthHandler{
 		oauthService: oauthService,
 		jwtManager:   jwtManager,
+		validate:     validation.Default(),
 	}
 }
 
+// ListProviders godoc
+// @Summary      OAuthプロバイダー一覧
+// @Description  ログインに使用できるOAuthプロバイダーの一覧を返します
+// @Tags         oauth
+// @Produce      json
+// @Success      200  {object}  OAuthProvidersResponse
+// @Router       /auth/providers [get]
+func (h *OAuthHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, OAuthProvidersResponse{Providers: oauth.ProviderNames()})
+}
+
 // BeginAuthHandler godoc
 // @Summary      OAuth認証開始
-// @Description  指定したプロバイダーでOAuth認証を開始します
+// @Description  指定したプロバイダーでOAuth認証を開始します。
+// @Description  redirect_uriを指定した場合、コールバック後に認可コードを付けてリダイレクトします（POST /auth/oauth/exchangeでトークンと交換）。
+// @Description  ループバックアドレス（http://127.0.0.1:{port}/...）へのリダイレクトはcode_challenge（S256）が必須です。
+// @Description  link=1を指定した場合は、POST /api/me/identities/{provider}で連携トークンのCookieを受け取ったブラウザでのみ、ログイン中のアカウントへの連携として扱います
 // @Tags         oauth
 // @Produce      json
-// @Param        provider  path  string  true  "プロバイダー名"  Enums(github, google)
-// @Success      307       {string}  string  "リダイレクト"
-// @Failure      400       {object}  ErrorResponse
-// @Failure      500       {object}  ErrorResponse
+// @Param        provider               path   string  true   "プロバイダー名（github, google, または設定したOIDCプロバイダー）"
+// @Param        redirect_uri           query  string  false  "ログイン後のリダイレクト先（許可リストまたはループバックアドレス）"
+// @Param        code_challenge         query  string  false  "PKCEのcode_challenge"
+// @Param        code_challenge_method  query  string  false  "PKCEのcode_challenge_method"  Enums(S256)
+// @Param        link                   query  string  false  "ログイン中のアカウントへの連携"  Enums(1)
+// @Success      307                    {string}  string  "リダイレクト"
+// @Failure      400                    {object}  ProblemDetails
+// @Failure      401                    {object}  ProblemDetails
+// @Failure      500                    {object}  ProblemDetails
 // @Router       /auth/{provider} [get]
 func (h *OAuthHandler) BeginAuthHandler(c *gin.Context) {
 	defer func() {
 		if r := recover(); r != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			respondError(c, fmt.Errorf("oauth handler panic: %v", r))
 		}
 	}()
 
 	provider := c.Param("provider")
 	if !isValidProvider(provider) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid provider"})
+		respondError(c, errInvalidProvider)
 		return
 	}
 
-	// デバッグログ
-	fmt.Printf("Starting OAuth for provider: %s\n", provider)
+	// リダイレクト先があればコールバックまでCookieで保持する。ない場合は以前の状態を消去する
+	if redirectURI := c.Query("redirect_uri"); redirectURI != "" {
+		redirect, err := h.oauthService.ValidateRedirect(redirectURI, c.Query("code_challenge"), c.Query("code_challenge_method"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		value := url.Values{"redirect_uri": {redirect.RedirectURI}, "code_challenge": {redirect.CodeChallenge}}
+		setOAuthCookie(c, oauthRedirectCookie, value.Encode())
+	} else {
+		clearOAuthCookie(c, oauthRedirectCookie)
+	}
+
+	// 連携の場合はStartLinkがこのブラウザに保存した連携トークンのCookieをコールバックまで保持する。
+	// URLの連携トークンは受け付けない（他人に開かせると、その人のOAuthアカウントが連携されてしまうため）。
+	// 連携でない場合は以前の連携状態を消去する
+	if c.Query("link") == "1" {
+		linkToken, err := c.Cookie(oauthLinkCookie)
+		if err != nil || linkToken == "" {
+			respondError(c, errInvalidLinkToken)
+			return
+		}
+		if _, err := h.oauthService.VerifyLinkToken(linkToken); err != nil {
+			clearOAuthCookie(c, oauthLinkCookie)
+			respondError(c, errInvalidLinkToken.Wrap(err))
+			return
+		}
+	} else {
+		clearOAuthCookie(c, oauthLinkCookie)
+	}
+
+	logging.FromContext(c.Request.Context()).Debug("starting oauth", "provider", provider)
 
 	// プロバイダーを設定
 	req := c.Request.WithContext(context.WithValue(c.Request.Context(), "provider", provider))
@@ -59,28 +158,36 @@ func (h *OAuthHandler) BeginAuthHandler(c *gin.Context) {
 
 // CallbackAuthHandler godoc
 // @Summary      OAuth認証コールバック
-// @Description  OAuth認証のコールバックを処理し、JWTトークンを発行します
+// @Description  OAuth認証のコールバックを処理し、JWTトークンを発行します。
+// @Description  二要素認証が有効なユーザーの場合はトークンの代わりにMFARequiredResponse（mfa_token）を返すので、/auth/login/mfaでコードを送信してください。
+// @Description  認証開始時にredirect_uriを指定した場合は、トークンの代わりに認可コード（code）を付けてredirect_uriへリダイレクトします。
+// @Description  エラー時はerrorとerror_descriptionを付けてリダイレクトします
 // @Tags         oauth
 // @Produce      json
-// @Param        provider  path  string  true  "プロバイダー名"  Enums(github, google)
+// @Param        provider  path  string  true  "プロバイダー名（github, google, または設定したOIDCプロバイダー）"
 // @Success      200       {object}  OAuthResponse
-// @Failure      400       {object}  ErrorResponse
-// @Failure      401       {object}  ErrorResponse
-// @Failure      500       {object}  ErrorResponse
+// @Success      302       {string}  string  "redirect_uriへリダイレクト"
+// @Failure      400       {object}  ProblemDetails
+// @Failure      401       {object}  ProblemDetails
+// @Failure      403       {object}  ProblemDetails
+// @Failure      409       {object}  ProblemDetails
+// @Failure      500       {object}  ProblemDetails
 // @Router       /auth/{provider}/callback [get]
 func (h *OAuthHandler) CallbackAuthHandler(c *gin.Context) {
 	defer func() {
 		if r := recover(); r != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			respondError(c, fmt.Errorf("oauth handler panic: %v", r))
 		}
 	}()
 
 	provider := c.Param("provider")
 	if !isValidProvider(provider) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid provider"})
+		respondError(c, errInvalidProvider)
 		return
 	}
 
+	redirect := h.consumeRedirect(c)
+
 	// プロバイダーを設定
 	req := c.Request.WithContext(context.WithValue(c.Request.Context(), "provider", provider))
 	c.Request = req
@@ -88,50 +195,377 @@ func (h *OAuthHandler) CallbackAuthHandler(c *gin.Context) {
 	// OAuth認証を完了
 	user, err := gothic.CompleteUserAuth(c.Writer, c.Request)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "oauth authentication failed"})
+		respondOAuthError(c, redirect, "access_denied", errOAuthAuthenticationFailed.Wrap(err))
 		return
 	}
 
-	// ユーザー情報を取得
-	username := user.Name
-	if username == "" {
-		username = user.NickName
+	identity := &service.OAuthIdentity{
+		Provider:       provider,
+		ProviderUserID: user.UserID,
+		Name:           user.Name,
+		NickName:       user.NickName,
+		Email:          user.Email,
+		AccessToken:    user.AccessToken,
+		RefreshToken:   user.RefreshToken,
+		TokenExpiry:    user.ExpiresAt,
 	}
-	if username == "" {
-		username = strings.Split(user.Email, "@")[0] // メールアドレスの@前をユーザー名として使用
+
+	// ログイン中のアカウントへの連携
+	if linkToken, err := c.Cookie(oauthLinkCookie); err == nil && linkToken != "" {
+		clearOAuthCookie(c, oauthLinkCookie)
+		h.completeLink(c, linkToken, identity, redirect)
+		return
 	}
 
-	// OAuthサービスでユーザー処理
-	tokenPair, err := h.oauthService.HandleOAuthCallback(
-		context.Background(),
-		username,
-		provider,
-		user.UserID,
-		user.Email,
-		user.AccessToken,
-		user.RefreshToken,
-		user.ExpiresAt,
-	)
+	// フロントエンドへ認可コードを付けてリダイレクト
+	if redirect != nil {
+		code, err := h.oauthService.HandleOAuthCallbackWithCode(c.Request.Context(), identity, redirect)
+		if err != nil {
+			var signupErr *service.SignupRequiredError
+			if errors.As(err, &signupErr) {
+				redirectWithParams(c, redirect.RedirectURI, url.Values{
+					"signup_token":       {signupErr.Token},
+					"suggested_username": {signupErr.SuggestedUsername},
+				})
+				return
+			}
+			respondOAuthCallbackError(c, redirect, err)
+			return
+		}
+		redirectWithParams(c, redirect.RedirectURI, url.Values{"code": {code}})
+		return
+	}
 
+	// OAuthサービスでユーザー処理
+	result, err := h.oauthService.HandleOAuthCallback(c.Request.Context(), identity, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process oauth user"})
+		var signupErr *service.SignupRequiredError
+		if errors.As(err, &signupErr) {
+			c.JSON(http.StatusOK, OAuthSignupRequiredResponse{
+				Message:           localize(c, "messages.signup_required"),
+				MessageKey:        "messages.signup_required",
+				SignupRequired:    true,
+				SignupToken:       signupErr.Token,
+				SuggestedUsername: signupErr.SuggestedUsername,
+				ExpiresIn:         int64(signupErr.ExpiresIn.Seconds()),
+			})
+			return
+		}
+		respondOAuthCallbackError(c, nil, err)
+		return
+	}
+
+	// 二要素認証が有効なユーザーは/auth/login/mfaでコードを送信する
+	if result.MFARequired {
+		respondMFARequired(c, result)
 		return
 	}
 
 	// 成功レスポンス
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "oauth authentication successful",
-		"access_token":  tokenPair.AccessToken,
-		"refresh_token": tokenPair.RefreshToken,
-		"expires_in":    tokenPair.ExpiresIn,
+		"message":       localize(c, "messages.oauth_login_successful"),
+		"message_key":   "messages.oauth_login_successful",
+		"access_token":  result.TokenPair.AccessToken,
+		"refresh_token": result.TokenPair.RefreshToken,
+		"expires_in":    result.TokenPair.ExpiresIn,
 		"user": gin.H{
-			"username": username,
-			"email":    user.Email,
+			"username": result.User.Username,
+			"email":    result.User.Email,
 			"provider": provider,
 		},
 	})
 }
 
+// ExchangeCode godoc
+// @Summary      OAuth認可コードの交換
+// @Description  OAuthログイン後にredirect_uriで受け取った認可コードをトークンペアと交換します。コードは1回のみ・発行から1分間有効です。
+// @Description  二要素認証が有効なユーザーの場合はトークンの代わりにMFARequiredResponse（mfa_token）を返します
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  OAuthExchangeRequest  true  "認可コード"
+// @Success      200   {object}  TokenResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/oauth/exchange [post]
+func (h *OAuthHandler) ExchangeCode(c *gin.Context) {
+	var req OAuthExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	result, err := h.oauthService.ExchangeCode(c.Request.Context(), req.Code, req.RedirectURI, req.CodeVerifier, clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if result.MFARequired {
+		respondMFARequired(c, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       localize(c, "messages.login_successful"),
+		"message_key":   "messages.login_successful",
+		"access_token":  result.TokenPair.AccessToken,
+		"refresh_token": result.TokenPair.RefreshToken,
+		"expires_in":    result.TokenPair.ExpiresIn,
+	})
+}
+
+// CompleteSignup godoc
+// @Summary      OAuth新規登録の完了
+// @Description  初めてのOAuthログインで返されたsignup_tokenとユーザー名を送信して登録を完了します。usernameを省略すると提案されたユーザー名を使用します
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  OAuthSignupRequest  true  "登録情報"
+// @Success      200   {object}  TokenResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/oauth/signup [post]
+func (h *OAuthHandler) CompleteSignup(c *gin.Context) {
+	var req OAuthSignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	tokenPair, err := h.oauthService.CompleteSignup(c.Request.Context(), req.SignupToken, req.Username, req.CodeVerifier, clientInfo(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       localize(c, "messages.signup_successful"),
+		"message_key":   "messages.signup_successful",
+		"access_token":  tokenPair.AccessToken,
+		"refresh_token": tokenPair.RefreshToken,
+		"expires_in":    tokenPair.ExpiresIn,
+	})
+}
+
+// completeLink は連携トークンのユーザーにOAuthアカウントを連携します。
+func (h *OAuthHandler) completeLink(c *gin.Context, linkToken string, identity *service.OAuthIdentity, redirect *service.OAuthRedirect) {
+	userID, err := h.oauthService.VerifyLinkToken(linkToken)
+	if err != nil {
+		respondOAuthError(c, redirect, "invalid_link_token", errInvalidLinkToken.Wrap(err))
+		return
+	}
+
+	if err := h.oauthService.LinkAccount(c.Request.Context(), userID, identity); err != nil {
+		respondOAuthCallbackError(c, redirect, err)
+		return
+	}
+
+	if redirect != nil {
+		redirectWithParams(c, redirect.RedirectURI, url.Values{"linked": {identity.Provider}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.account_linked"), "message_key": "messages.account_linked", "provider": identity.Provider})
+}
+
+// consumeRedirect は認証開始時に保存したリダイレクト先を取り出して再検証します。Cookieは削除します。
+func (h *OAuthHandler) consumeRedirect(c *gin.Context) *service.OAuthRedirect {
+	raw, err := c.Cookie(oauthRedirectCookie)
+	if err != nil || raw == "" {
+		return nil
+	}
+	clearOAuthCookie(c, oauthRedirectCookie)
+
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil
+	}
+	method := ""
+	if values.Get("code_challenge") != "" {
+		method = service.PKCEMethodS256
+	}
+	redirect, err := h.oauthService.ValidateRedirect(values.Get("redirect_uri"), values.Get("code_challenge"), method)
+	if err != nil {
+		return nil
+	}
+	return redirect
+}
+
+// respondOAuthCallbackError はOAuthコールバック・連携のエラーを返します。
+func respondOAuthCallbackError(c *gin.Context, redirect *service.OAuthRedirect, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserBanned):
+		respondOAuthError(c, redirect, "account_banned", err)
+	case errors.Is(err, service.ErrPasswordResetRequired):
+		respondOAuthError(c, redirect, "password_reset_required", err)
+	case errors.Is(err, service.ErrOAuthEmailInUse):
+		respondOAuthError(c, redirect, "email_in_use", err)
+	case errors.Is(err, service.ErrOAuthIdentityInUse):
+		respondOAuthError(c, redirect, "identity_in_use", err)
+	case errors.Is(err, service.ErrProviderAlreadyLinked):
+		respondOAuthError(c, redirect, "provider_already_linked", err)
+	case errors.Is(err, service.ErrUserNotFound):
+		respondOAuthError(c, redirect, "user_not_found", errUnauthorized.Wrap(err))
+	default:
+		respondOAuthError(c, redirect, "server_error", err)
+	}
+}
+
+// respondOAuthError はリダイレクト先があればerrorとerror_descriptionを付けてリダイレクトし、なければproblem+jsonでエラーを返します。
+// リダイレクトのerrorにはフロントエンドが判定に使う従来のコードを設定します。内部エラーの内容はログにだけ出力します。
+func respondOAuthError(c *gin.Context, redirect *service.OAuthRedirect, code string, err error) {
+	if redirect == nil {
+		respondError(c, err)
+		return
+	}
+	description := errOAuthFailed.Message
+	if appErr, ok := apperror.As(err); ok {
+		description = appErr.Message
+	} else {
+		logging.FromContext(c.Request.Context()).Error("oauth callback failed", "error", err)
+	}
+	redirectWithParams(c, redirect.RedirectURI, url.Values{"error": {code}, "error_description": {description}})
+}
+
+// redirectWithParams はリダイレクト先のクエリにパラメーターを追加してリダイレクトします。
+func redirectWithParams(c *gin.Context, redirectURI string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		respondError(c, fmt.Errorf("failed to parse redirect_uri: %w", err))
+		return
+	}
+	q := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	c.Redirect(http.StatusFound, u.String())
+}
+
+func setOAuthCookie(c *gin.Context, name, value string) {
+	c.SetCookie(name, value, oauthCookieMaxAge, "/auth", "", c.Request.TLS != nil, true)
+}
+
+func clearOAuthCookie(c *gin.Context, name string) {
+	c.SetCookie(name, "", -1, "/auth", "", c.Request.TLS != nil, true)
+}
+
+// StartLink godoc
+// @Summary      OAuthプロバイダーの連携開始
+// @Description  ログイン中のアカウントにOAuthプロバイダーを連携するための認可URLを返します。
+// @Description  連携トークンはoauth_link_token Cookie（HttpOnly）で返すため、同じブラウザでauthorize_urlを開くと連携が完了します。
+// @Description  別のブラウザや他のユーザーがauthorize_urlを開いても連携は行われません
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Param        provider  path  string  true  "プロバイダー名（github, google, または設定したOIDCプロバイダー）"
+// @Success      200       {object}  dtos.OAuthLinkResponse
+// @Failure      400       {object}  ProblemDetails
+// @Failure      401       {object}  ProblemDetails
+// @Failure      403       {object}  ProblemDetails
+// @Failure      500       {object}  ProblemDetails
+// @Router       /api/me/identities/{provider} [post]
+func (h *OAuthHandler) StartLink(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	provider := c.Param("provider")
+	if !isValidProvider(provider) {
+		respondError(c, errInvalidProvider)
+		return
+	}
+
+	link, err := h.oauthService.StartLink(c.Request.Context(), userID, provider)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// 連携トークンはこのリクエストを送ったブラウザにだけ渡す
+	setOAuthCookie(c, oauthLinkCookie, link.LinkToken)
+	c.JSON(http.StatusOK, dtos.OAuthLinkResponse{
+		AuthorizeURL: link.AuthorizeURL,
+		ExpiresIn:    int64(link.ExpiresIn.Seconds()),
+	})
+}
+
+// ListIdentities godoc
+// @Summary      ログイン方法の一覧
+// @Description  パスワードの設定有無と、連携済みのOAuthプロバイダーの一覧を返します
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {object}  dtos.IdentitiesResponse
+// @Failure      401  {object}  ProblemDetails
+// @Failure      403  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/identities [get]
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	res, err := h.oauthService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// UnlinkIdentity godoc
+// @Summary      OAuthプロバイダーの連携解除
+// @Description  連携済みのOAuthプロバイダーを解除します。最後のログイン方法は解除できません
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Param        identityId  path  int  true  "Identity ID"
+// @Success      200         {object}  MessageResponse
+// @Failure      400         {object}  ProblemDetails
+// @Failure      401         {object}  ProblemDetails
+// @Failure      403         {object}  ProblemDetails
+// @Failure      404         {object}  ProblemDetails
+// @Failure      409         {object}  ProblemDetails
+// @Failure      500         {object}  ProblemDetails
+// @Router       /api/me/identities/{identityId} [delete]
+func (h *OAuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	identityID, err := strconv.ParseUint(c.Param("identityId"), 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("identityId", err))
+		return
+	}
+
+	if err := h.oauthService.UnlinkAccount(c.Request.Context(), userID, uint(identityID)); err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.identity_unlinked")
+}
+
 // RefreshTokenHandler godoc
 // @Summary      OAuthトークン更新
 // @Description  リフレッシュトークンを使用して新しいトークンペアを生成します
@@ -140,22 +574,27 @@ func (h *OAuthHandler) CallbackAuthHandler(c *gin.Context) {
 // @Produce      json
 // @Param        body  body  RefreshTokenRequest  true  "リフレッシュトークン"
 // @Success      200   {object}  TokenResponse
-// @Failure      400   {object}  ErrorResponse
-// @Failure      401   {object}  ErrorResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
 // @Router       /auth/oauth/refresh [post]
 func (h *OAuthHandler) RefreshTokenHandler(c *gin.Context) {
 	var req struct {
-		RefreshToken string `json:"refresh_token" binding:"required"`
+		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh token is required"})
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
 		return
 	}
 
-	tokenPair, err := h.jwtManager.RefreshTokenPair(req.RefreshToken)
+	tokenPair, err := h.oauthService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		respondError(c, err)
 		return
 	}
 
@@ -168,25 +607,40 @@ func (h *OAuthHandler) RefreshTokenHandler(c *gin.Context) {
 
 // LogoutHandler godoc
 // @Summary      OAuthログアウト
-// @Description  OAuthログアウト処理を行います
+// @Description  リフレッシュトークンが属するセッションを失効させます
 // @Tags         oauth
 // @Accept       json
 // @Produce      json
+// @Param        body  body  RefreshTokenRequest  true  "リフレッシュトークン"
 // @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
 // @Router       /auth/oauth/logout [post]
 func (h *OAuthHandler) LogoutHandler(c *gin.Context) {
-	// クライアント側でトークンを削除することを前提とする
-	// 必要に応じてブラックリストにトークンを追加する処理を追加可能
-	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
+	var req struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.oauthService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.logout_successful")
 }
 
 // isValidProvider 有効なプロバイダーかどうかをチェック
+// 設定により登録されたプロバイダーのみ有効とする
 func isValidProvider(provider string) bool {
-	validProviders := []string{"github", "google"}
-	for _, p := range validProviders {
-		if p == provider {
-			return true
-		}
-	}
-	return false
+	return oauth.IsEnabled(provider)
 }
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+)
+
+const (
+	fakeProviderName = "fakeprovider"
+	attackerID       = uint(1)
+)
+
+// fakeProvider は認可サーバーに接続せず、常に同じユーザー（被害者）を返すgothのプロバイダーです。
+type fakeProvider struct{}
+
+type fakeSession struct {
+	AuthURL string `json:"auth_url"`
+}
+
+func (s *fakeSession) GetAuthURL() (string, error) { return s.AuthURL, nil }
+func (s *fakeSession) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+func (s *fakeSession) Authorize(goth.Provider, goth.Params) (string, error) { return "access", nil }
+
+func (fakeProvider) Name() string                { return fakeProviderName }
+func (fakeProvider) SetName(string)              {}
+func (fakeProvider) Debug(bool)                  {}
+func (fakeProvider) RefreshTokenAvailable() bool { return false }
+func (fakeProvider) RefreshToken(string) (*oauth2.Token, error) {
+	return nil, nil
+}
+func (fakeProvider) BeginAuth(state string) (goth.Session, error) {
+	return &fakeSession{AuthURL: "https://provider.example.com/authorize?state=" + url.QueryEscape(state)}, nil
+}
+func (fakeProvider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &fakeSession{}
+	err := json.Unmarshal([]byte(data), s)
+	return s, err
+}
+func (fakeProvider) FetchUser(goth.Session) (goth.User, error) {
+	return goth.User{Provider: fakeProviderName, UserID: "victim-provider-id", AccessToken: "access"}, nil
+}
+
+// 連携で呼ばれるリポジトリのメソッドだけを実装したフェイク。その他のメソッドが呼ばれるとpanicする
+type fakeUserRepo struct{ repository.UserRepository }
+
+func (fakeUserRepo) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	return &models.User{ID: id, Username: "attacker", Role: models.RoleUser}, nil
+}
+
+type fakeOAuthRepo struct {
+	repository.OAuthAccountRepository
+	linked []*models.OAuthAccount
+}
+
+func (r *fakeOAuthRepo) FindByProviderAndProviderUserID(ctx context.Context, provider, providerUserID string) (*models.OAuthAccount, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOAuthRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.OAuthAccount, error) {
+	return nil, nil
+}
+
+func (r *fakeOAuthRepo) Create(ctx context.Context, account *models.OAuthAccount) error {
+	r.linked = append(r.linked, account)
+	return nil
+}
+
+type fakeSignupRepo struct {
+	repository.PendingOAuthSignupRepository
+}
+
+func (fakeSignupRepo) Create(ctx context.Context, signup *models.PendingOAuthSignup) error {
+	return nil
+}
+
+// browser はCookieを保持してリクエストを送るテスト用のクライアントです。
+type browser struct {
+	t       *testing.T
+	engine  *gin.Engine
+	cookies map[string]*http.Cookie
+}
+
+func newBrowser(t *testing.T, engine *gin.Engine) *browser {
+	return &browser{t: t, engine: engine, cookies: make(map[string]*http.Cookie)}
+}
+
+func (b *browser) do(method, target string) *httptest.ResponseRecorder {
+	b.t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	b.engine.ServeHTTP(w, req)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 || cookie.Value == "" {
+			delete(b.cookies, cookie.Name)
+		} else {
+			b.cookies[cookie.Name] = cookie
+		}
+	}
+	return w
+}
+
+// authorize は認証開始のリダイレクト先からstateを取り出し、プロバイダーから戻ったときのコールバックを送ります。
+// extraはコールバックのクエリに追加するパラメーターです。
+func (b *browser) authorize(begin *httptest.ResponseRecorder, extra url.Values) *httptest.ResponseRecorder {
+	b.t.Helper()
+	location, err := url.Parse(begin.Header().Get("Location"))
+	if err != nil || location.Query().Get("state") == "" {
+		b.t.Fatalf("begin did not redirect to the provider: status=%d location=%q body=%s", begin.Code, begin.Header().Get("Location"), begin.Body)
+	}
+	query := url.Values{"code": {"provider-code"}, "state": {location.Query().Get("state")}}
+	for k, vs := range extra {
+		query[k] = vs
+	}
+	return b.do(http.MethodGet, "/auth/"+fakeProviderName+"/callback?"+query.Encode())
+}
+
+func newLinkTestRouter(t *testing.T) (*gin.Engine, *fakeOAuthRepo, *token.JWTManager) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	goth.UseProviders(fakeProvider{})
+	t.Cleanup(goth.ClearProviders)
+	gothic.Store = sessions.NewCookieStore([]byte("test-session-secret-0123456789abcdef"))
+
+	jwtManager := token.NewJWTManager("test-access-secret", "test-refresh-secret", "ctfforge", time.Minute, time.Hour)
+	oauthRepo := &fakeOAuthRepo{}
+	oauthService := service.NewOAuthService(oauthRepo, fakeUserRepo{}, nil, fakeSignupRepo{}, nil, jwtManager, nil, true)
+	h := NewOAuthHandler(oauthService, jwtManager)
+
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/auth/:provider", h.BeginAuthHandler)
+	r.GET("/auth/:provider/callback", h.CallbackAuthHandler)
+	// 認証ミドルウェアの代わりに攻撃者としてログインしている状態にする
+	r.POST("/api/me/identities/:provider", func(c *gin.Context) {
+		c.Set("user_id", attackerID)
+		h.StartLink(c)
+	})
+	return r, oauthRepo, jwtManager
+}
+
+func TestOAuthLinkIsBoundToTheStartingBrowser(t *testing.T) {
+	r, oauthRepo, jwtManager := newLinkTestRouter(t)
+
+	attacker := newBrowser(t, r)
+	w := attacker.do(http.MethodPost, "/api/me/identities/"+fakeProviderName)
+	if w.Code != http.StatusOK {
+		t.Fatalf("StartLink status = %d, body = %s", w.Code, w.Body)
+	}
+	var res struct {
+		AuthorizeURL string `json:"authorize_url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res.AuthorizeURL, "link_token") {
+		t.Fatalf("authorize_url %q contains the link token", res.AuthorizeURL)
+	}
+	cookie, ok := attacker.cookies[oauthLinkCookie]
+	if !ok || !cookie.HttpOnly {
+		t.Fatalf("StartLink did not set an HttpOnly %s cookie", oauthLinkCookie)
+	}
+
+	t.Run("victim opening the attacker's authorize_url", func(t *testing.T) {
+		victim := newBrowser(t, r)
+		w := victim.do(http.MethodGet, res.AuthorizeURL)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("begin status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("victim given the attacker's link token in the query", func(t *testing.T) {
+		victim := newBrowser(t, r)
+		begin := victim.do(http.MethodGet, "/auth/"+fakeProviderName+"?link_token="+url.QueryEscape(cookie.Value))
+		if _, ok := victim.cookies[oauthLinkCookie]; ok {
+			t.Fatalf("begin stored the link token from the query")
+		}
+		w := victim.authorize(begin, nil)
+		if len(oauthRepo.linked) != 0 {
+			t.Fatalf("callback linked %d accounts to user %d, want none", len(oauthRepo.linked), oauthRepo.linked[0].UserID)
+		}
+		if !strings.Contains(w.Body.String(), "messages.signup_required") {
+			t.Fatalf("callback response = %s, want the victim's own sign-up", w.Body)
+		}
+	})
+
+	t.Run("victim given a link token of another session", func(t *testing.T) {
+		linkToken, err := jwtManager.GenerateOAuthLinkToken(attackerID, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		victim := newBrowser(t, r)
+		begin := victim.do(http.MethodGet, "/auth/"+fakeProviderName)
+		// コールバックに別のセッションの連携トークンを付けても、このブラウザで連携を開始していなければ連携しない
+		w := victim.authorize(begin, url.Values{"link_token": {linkToken}})
+		if len(oauthRepo.linked) != 0 {
+			t.Fatalf("callback linked an account with a link token of another session: %s", w.Body)
+		}
+		if !strings.Contains(w.Body.String(), "messages.signup_required") {
+			t.Fatalf("callback response = %s, want the victim's own sign-up", w.Body)
+		}
+	})
+
+	t.Run("attacker's own browser", func(t *testing.T) {
+		w := attacker.authorize(attacker.do(http.MethodGet, res.AuthorizeURL), nil)
+		if w.Code != http.StatusOK || len(oauthRepo.linked) != 1 || oauthRepo.linked[0].UserID != attackerID {
+			t.Fatalf("callback status = %d, linked = %v, body = %s; want the account linked to user %d", w.Code, oauthRepo.linked, w.Body, attackerID)
+		}
+	})
+}
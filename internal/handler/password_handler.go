@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService *service.PasswordResetService
+	validate             *validator.Validate
+}
+
+func NewPasswordResetHandler(passwordResetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+		validate:             validation.Default(),
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"test@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required" example:"q1w2e3r4t5y6..."`
+	NewPassword string `json:"new_password" validate:"required,min=8" example:"newpassword123"`
+}
+
+// ForgotPassword godoc
+// @Summary      パスワードリセットの申請
+// @Description  登録済みのメールアドレスにパスワード再設定用のリンクを送信します。メールアドレスが未登録でも同じレスポンスを返します
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  ForgotPasswordRequest  true  "メールアドレス"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/password/forgot [post]
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.password_reset_requested")
+}
+
+// ResetPassword godoc
+// @Summary      パスワードの再設定
+// @Description  メールで受け取ったトークンを使ってパスワードを再設定します。再設定後は既存のセッションがすべて失効します
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  ResetPasswordRequest  true  "リセットトークンと新しいパスワード"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /auth/password/reset [post]
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.password_reset")
+}
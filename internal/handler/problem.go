@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+// ProblemContentType はエラーレスポンスのContent-Typeです（RFC 7807）。
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails はエラーレスポンスです（RFC 7807）。
+type ProblemDetails struct {
+	Type       string `json:"type" example:"about:blank"`
+	Title      string `json:"title" example:"Not Found"`
+	Status     int    `json:"status" example:"404"`
+	Detail     string `json:"detail,omitempty" example:"Challenge not found."` // リクエストの言語のメッセージ
+	Instance   string `json:"instance,omitempty" example:"/api/challenges/42"`
+	Code       string `json:"code" example:"challenge_not_found"`               // 機械判定用の識別子
+	MessageKey string `json:"message_key" example:"errors.challenge_not_found"` // detailのメッセージのキー
+	RequestID  string `json:"request_id,omitempty" example:"3f2a9c1e8b7d4a6f0e5c2b1a9d8c7e6f"`
+
+	Errors []validation.FieldError `json:"errors,omitempty"` // 入力の検証エラー（400の場合のみ）
+}
+
+// 種類の決まっていないエラー（内部エラー）のレスポンス
+var errInternal = apperror.New(apperror.KindInternal, "internal_error", "internal server error")
+
+// ErrorHandler はハンドラーやミドルウェアが gin.Context.Error で登録したエラーを
+// application/problem+json のレスポンスに変換するミドルウェアです。
+// *apperror.Error はKindに対応するステータスコードと、Codeに対応するリクエストの言語のメッセージを返します。
+// それ以外のエラーは500として内容をログにだけ出力します。
+// 原因（apperror.Error.Err）もログにだけ出力します。ただし原因が入力の検証エラーの場合は、
+// フィールドごとのエラーをAccept-Languageの言語でerrorsに設定します。
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		logger := logging.FromContext(c.Request.Context())
+		if c.Writer.Written() {
+			logger.Error("error after response was written", "error", err)
+			return
+		}
+
+		appErr, ok := apperror.As(err)
+		if !ok {
+			appErr = errInternal
+		}
+		status := appErr.Kind.Status()
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("request failed", "error", err)
+		case appErr.Err != nil:
+			logger.Warn("request rejected", "code", appErr.Code, "error", err)
+		}
+		if appErr.Kind == apperror.KindRateLimited && appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(appErr.RetryAfter.Seconds())+1))
+		}
+
+		locale := i18n.FromContext(c.Request.Context())
+		var fieldErrors []validation.FieldError
+		if appErr.Kind == apperror.KindValidation {
+			fieldErrors = validation.Fields(appErr.Err, locale)
+		}
+		messageKey := "errors." + appErr.Code
+		detail, ok := i18n.Lookup(locale, messageKey, appErr.Params)
+		if !ok {
+			detail = appErr.Message
+		}
+
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, ProblemDetails{
+			Type:       "about:blank",
+			Title:      http.StatusText(status),
+			Status:     status,
+			Detail:     detail,
+			Instance:   c.Request.URL.Path,
+			Code:       appErr.Code,
+			MessageKey: messageKey,
+			RequestID:  logging.RequestID(c.Request.Context()),
+			Errors:     fieldErrors,
+		})
+	}
+}
+
+// respondError はエラーを登録してハンドラーの処理を中断します。レスポンスはErrorHandlerが返します。
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// NoRoute はどのルートにも一致しないリクエストに404を返します。
+func NoRoute(c *gin.Context) {
+	respondError(c, apperror.NotFound("route_not_found", "the requested resource does not exist"))
+}
+
+// errUnauthorized は認証済みのユーザーを特定できない場合のエラーです。
+var errUnauthorized = apperror.Unauthorized("unauthorized", "unauthorized")
+
+// errInvalidRequest はリクエストボディやパラメーターを解釈できない場合のエラーです。
+var errInvalidRequest = apperror.Validation("invalid_request", "invalid request")
+
+// invalidRequest はリクエストの解釈に失敗したエラーを返します。原因はログにだけ出力します。
+func invalidRequest(err error) error {
+	return errInvalidRequest.Wrap(err)
+}
+
+// errValidationFailed は入力の検証に失敗した場合のエラーです。
+var errValidationFailed = apperror.Validation("validation_failed", "request validation failed")
+
+// validationFailed は入力の検証に失敗したエラーを返します。フィールドごとのエラーはErrorHandlerが原因から作成します。
+func validationFailed(err error) error {
+	return errValidationFailed.Wrap(err)
+}
+
+// invalidParam はパスパラメーターやクエリパラメーターを解釈できない場合のエラーを返します。nameはパラメーター名です。
+func invalidParam(name string, err error) error {
+	return apperror.Validation("invalid_parameter", "invalid parameter").WithParam("name", name).Wrap(err)
+}
+
+// respondCurrentUserError は認証済みユーザー自身に対する操作のエラーを返します。
+// トークンの発行後にユーザーが削除されている場合（ErrUserNotFound）は401を返します。
+func respondCurrentUserError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUserNotFound) {
+		err = errUnauthorized.Wrap(err)
+	}
+	respondError(c, err)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProfileHandler struct {
+	profileService *service.ProfileService
+	validate       *validator.Validate
+}
+
+func NewProfileHandler(profileService *service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		validate:       validation.Default(),
+	}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+	NewPassword     string `json:"new_password" validate:"required,min=8" example:"newpassword123"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email" example:"new@example.com"`
+	CurrentPassword string `json:"current_password" example:"password123"` // パスワードが設定されているアカウントでは必須
+}
+
+// UpdateProfile godoc
+// @Summary      プロフィールの更新
+// @Description  ユーザー名・表示名・自己紹介・アイコン画像のURL・解いた問題の公開設定を更新します。省略した項目は変更しません。ユーザー名は一定期間に1回のみ変更できます
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dtos.UpdateProfileRequest  true  "プロフィール"
+// @Success      200   {object}  MeResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      429   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me [patch]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	var req dtos.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newMeResponse(user))
+}
+
+// ChangePassword godoc
+// @Summary      パスワードの変更
+// @Description  現在のパスワードを確認してパスワードを変更します。変更後は現在のセッション以外のセッションがすべて失効します
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ChangePasswordRequest  true  "現在のパスワードと新しいパスワード"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/password [post]
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+	sessionID, _ := token.GetSessionID(c)
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.profileService.ChangePassword(c.Request.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.password_changed")
+}
+
+// ChangeEmail godoc
+// @Summary      メールアドレスの変更
+// @Description  新しいメールアドレスに確認リンクを送信します。リンクを開いて確認するまでメールアドレスは変更されません
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ChangeEmailRequest  true  "新しいメールアドレス"
+// @Success      202   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      403   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      429   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/email [post]
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return
+	}
+
+	if err := h.profileService.RequestEmailChange(c.Request.Context(), userID, req.NewEmail, req.CurrentPassword); err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusAccepted, "messages.email_change_requested")
+}
+
+// GetPublicProfile godoc
+// @Summary      公開プロフィールの取得
+// @Description  ユーザーの公開プロフィール、作成した公開問題、解いた問題、合計スコア・順位、カテゴリー別の成績を返します。解いた問題を非表示にしているユーザーの場合、本人以外にはsolvesとcategoriesを返しません
+// @Tags         user
+// @Produce      json
+// @Param        username  path      string  true  "ユーザー名"
+// @Success      200       {object}  dtos.PublicProfileResponse
+// @Failure      404       {object}  ProblemDetails
+// @Failure      500       {object}  ProblemDetails
+// @Router       /api/public/users/{username} [get]
+func (h *ProfileHandler) GetPublicProfile(c *gin.Context) {
+	viewerID, _ := token.GetUserID(c)
+
+	profile, err := h.profileService.GetPublicProfile(c.Request.Context(), c.Param("username"), viewerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	tokenService *service.TokenService
+}
+
+func NewSessionHandler(tokenService *service.TokenService) *SessionHandler {
+	return &SessionHandler{tokenService: tokenService}
+}
+
+// ListSessions godoc
+// @Summary      ログイン中のセッション一覧
+// @Description  自分の有効なセッション（ログイン中の端末）の一覧を返します
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {array}   dtos.SessionDTO
+// @Failure      401  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+	sessionID, _ := token.GetSessionID(c)
+
+	sessions, err := h.tokenService.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary      セッションを失効
+// @Description  指定したセッションからサインアウトします
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Param        sessionId  path  string  true  "Session ID"
+// @Success      200        {object}  MessageResponse
+// @Failure      401        {object}  ProblemDetails
+// @Failure      404        {object}  ProblemDetails
+// @Failure      500        {object}  ProblemDetails
+// @Router       /api/me/sessions/{sessionId} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if err := h.tokenService.RevokeSession(c.Request.Context(), userID, c.Param("sessionId")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.session_revoked")
+}
+
+// RevokeOtherSessions godoc
+// @Summary      他のセッションをすべて失効
+// @Description  現在のセッション以外のすべてのセッションからサインアウトします
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {object}  MessageResponse
+// @Failure      401  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+	sessionID, _ := token.GetSessionID(c)
+
+	if err := h.tokenService.RevokeOtherSessions(c.Request.Context(), userID, sessionID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.other_sessions_revoked")
+}
+
+// clientInfo はリクエスト元のクライアント情報を取得します。
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/validation"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	validate         *validator.Validate
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		validate:         validation.Default(),
+	}
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// EnrollTOTP godoc
+// @Summary      二要素認証の登録開始
+// @Description  TOTPシークレットを生成し、認証アプリ登録用のotpauth URIを返します。confirmで確認するまで有効になりません
+// @Tags         user
+// @Security     bearer
+// @Produce      json
+// @Success      200  {object}  dtos.TOTPEnrollResponse
+// @Failure      401  {object}  ProblemDetails
+// @Failure      409  {object}  ProblemDetails
+// @Failure      500  {object}  ProblemDetails
+// @Router       /api/me/2fa/totp/enroll [post]
+func (h *TwoFactorHandler) EnrollTOTP(c *gin.Context) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	res, err := h.twoFactorService.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ConfirmTOTP godoc
+// @Summary      二要素認証の有効化
+// @Description  認証アプリのコードを確認して二要素認証を有効にし、リカバリーコードを返します
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      TOTPCodeRequest  true  "TOTPコード"
+// @Success      200   {object}  dtos.RecoveryCodesResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/2fa/totp/confirm [post]
+func (h *TwoFactorHandler) ConfirmTOTP(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      リカバリーコードの再発行
+// @Description  現在のコードを確認してリカバリーコードを再発行します。以前のリカバリーコードは無効になります
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      TOTPCodeRequest  true  "TOTPコード"
+// @Success      200   {object}  dtos.RecoveryCodesResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      429   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP godoc
+// @Summary      二要素認証の無効化
+// @Description  TOTPコードまたはリカバリーコードを確認して二要素認証を無効にします
+// @Tags         user
+// @Security     bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body      TOTPCodeRequest  true  "TOTPコードまたはリカバリーコード"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ProblemDetails
+// @Failure      401   {object}  ProblemDetails
+// @Failure      409   {object}  ProblemDetails
+// @Failure      429   {object}  ProblemDetails
+// @Failure      500   {object}  ProblemDetails
+// @Router       /api/me/2fa/totp/disable [post]
+func (h *TwoFactorHandler) DisableTOTP(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		respondCurrentUserError(c, err)
+		return
+	}
+
+	respondMessage(c, http.StatusOK, "messages.totp_disabled")
+}
+
+// bindCode は認証ユーザーIDとコードを含むリクエストボディを取得します。失敗時はレスポンスを書き込みfalseを返します。
+func (h *TwoFactorHandler) bindCode(c *gin.Context) (uint, *TOTPCodeRequest, bool) {
+	userID, exists := token.GetUserID(c)
+	if !exists {
+		respondError(c, errUnauthorized)
+		return 0, nil, false
+	}
+
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidRequest(err))
+		return 0, nil, false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondError(c, validationFailed(err))
+		return 0, nil, false
+	}
+
+	return userID, &req, true
+}
@@ -0,0 +1,130 @@
+// Package health はレディネスチェックの登録先を提供します。
+//
+// データベースやメール送信などのサブシステムはRegistryにチェックを登録します。
+// Registry.Checkはすべてのチェックを並行して実行し、コンポーネントごとの状態を返します。
+// 必須のチェックが1つでも失敗した場合、全体の状態はunavailableになります。
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// 状態
+const (
+	StatusOK          = "ok"          // すべてのチェックが成功
+	StatusDegraded    = "degraded"    // 必須でないチェックが失敗
+	StatusUnavailable = "unavailable" // 必須のチェックが失敗
+	StatusFailed      = "failed"      // コンポーネントのチェックが失敗
+)
+
+// CheckFunc はサブシステムの状態を確認し、利用できない場合はエラーを返します。
+type CheckFunc func(ctx context.Context) error
+
+// ComponentStatus はコンポーネントごとのチェック結果です。
+type ComponentStatus struct {
+	Status    string `json:"status" example:"ok"`
+	Required  bool   `json:"required" example:"true"`
+	LatencyMS int64  `json:"latency_ms" example:"3"`
+	Error     string `json:"error,omitempty" example:"timeout"` // 内部の情報を含まない概要。詳細はサーバーのログに出力
+}
+
+// Report はレディネスチェックの結果です。
+type Report struct {
+	Status     string                      `json:"status" example:"ok"`
+	Components map[string]*ComponentStatus `json:"components"`
+}
+
+// Ready はトラフィックを受け付けられるか（必須のチェックがすべて成功したか）を返します。
+func (r *Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+type check struct {
+	name     string
+	required bool
+	fn       CheckFunc
+}
+
+// Registry はチェックの登録先です。
+type Registry struct {
+	timeout time.Duration
+	checks  []check
+	names   map[string]bool
+}
+
+// NewRegistry は空のRegistryを作成します。timeoutは各チェックの制限時間です。
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout, names: make(map[string]bool)}
+}
+
+// Register はチェックを登録します。requiredのチェックが失敗するとレディネスチェック全体が失敗します。
+// 名前が空・重複している場合はプログラムの誤りのためpanicします。
+func (r *Registry) Register(name string, required bool, fn CheckFunc) {
+	if name == "" || fn == nil {
+		panic(fmt.Sprintf("health: check %q must have a name and a function", name))
+	}
+	if r.names[name] {
+		panic(fmt.Sprintf("health: duplicate check %q", name))
+	}
+	r.names[name] = true
+	r.checks = append(r.checks, check{name: name, required: required, fn: fn})
+}
+
+// Check は登録されたすべてのチェックを並行して実行します。
+func (r *Registry) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status:     StatusOK,
+		Components: make(map[string]*ComponentStatus, len(r.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := r.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[c.name] = status
+			if status.Status == StatusOK {
+				return
+			}
+			if c.required {
+				report.Status = StatusUnavailable
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) *ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	status := &ComponentStatus{
+		Status:    StatusOK,
+		Required:  c.required,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "health check failed", "component", c.name, "error", err)
+		status.Status = StatusFailed
+		status.Error = "check failed"
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			status.Error = "timeout"
+		}
+	}
+	return status
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+// catalogs は言語ごとのメッセージです。キーはYAMLの階層を "." でつないだもの（errors.user_not_found など）です。
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	catalogs := make(map[string]map[string]string, len(Supported))
+	for _, locale := range Supported {
+		data, err := localeFiles.ReadFile("locales/" + locale + ".yaml")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read catalog %s: %v", locale, err))
+		}
+		var tree map[string]any
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse catalog %s: %v", locale, err))
+		}
+		messages := make(map[string]string)
+		flatten("", tree, messages)
+		catalogs[locale] = messages
+	}
+	return catalogs
+}
+
+func flatten(prefix string, tree map[string]any, out map[string]string) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case string:
+			out[key] = v
+		default:
+			panic(fmt.Sprintf("i18n: message %s must be a string", key))
+		}
+	}
+}
+
+// Lookup はlocaleの言語のメッセージを返します。{name} はparamsの値に置き換えます。
+// localeにメッセージがない場合は既定の言語のメッセージを使用し、どちらにもない場合はfalseを返します。
+func Lookup(locale, key string, params map[string]string) (string, bool) {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return "", false
+	}
+	if len(params) == 0 {
+		return message, true
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(message), true
+}
+
+// T はlocaleの言語のメッセージを返します。メッセージがない場合はキーを返します。
+func T(locale, key string, params map[string]string) string {
+	if message, ok := Lookup(locale, key, params); ok {
+		return message
+	}
+	return key
+}
@@ -0,0 +1,71 @@
+// Package i18n はAPIのメッセージの言語（ロケール）とメッセージカタログを扱います。
+//
+// 対応する言語は日本語と英語です。Middlewareでリクエストの Accept-Language ヘッダーから言語を選択して
+// コンテキストに格納し、エラー・処理結果・メールのメッセージはTでその言語のカタログから作成します。
+// 対応する言語が含まれない場合は英語（Default）を使用します。
+// メッセージのキー（errors.user_not_found など）は言語によらず変わらないため、
+// クライアントはレスポンスの message_key で独自の表示に置き換えられます。
+package i18n
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+const (
+	English  = "en"
+	Japanese = "ja"
+
+	// Default はAccept-Languageがない場合や対応する言語がない場合の言語です。
+	Default = English
+)
+
+// Supported は対応する言語の一覧です。先頭が既定の言語です。
+var Supported = []string{English, Japanese}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Japanese})
+
+// Negotiate はAccept-Languageヘッダーの値から応答に使用する言語を選択します。
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return Supported[index]
+}
+
+type localeKey struct{}
+
+// WithLocale は言語を格納したコンテキストを返します。
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext はコンテキストに格納された言語を返します。格納されていない場合はDefaultを返します。
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return Default
+}
+
+// Middleware はAccept-Languageヘッダーから言語を選択し、リクエストのコンテキストに格納します。
+// 選択した言語はContent-Languageヘッダーで返します。
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := Negotiate(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
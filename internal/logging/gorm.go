@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// パラメーターを渡さずに展開したSQLでは、PostgreSQLのプレースホルダーが $1$ のように残るため $1 に戻す
+var placeholderPattern = regexp.MustCompile(`\$(\d+)\$`)
+
+// GormLogger はGORMのログをクエリのコンテキストのロガー（リクエストIDつき）に出力します。
+// SQLはプレースホルダーのまま出力し、パラメーター（メールアドレスやハッシュなど）は出力しません。
+type GormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger はGormLoggerのコンストラクタです。logSQLがtrueの場合は実行したすべてのSQLをInfoで出力します。
+// 失敗したクエリ（レコードが見つからない場合を除く）はError、slowThresholdより遅いクエリはWarnで出力します。
+func NewGormLogger(logSQL bool, slowThreshold time.Duration) *GormLogger {
+	level := gormlogger.Warn
+	if logSQL {
+		level = gormlogger.Info
+	}
+	return &GormLogger{level: level, slowThreshold: slowThreshold}
+}
+
+// LogMode はgormlogger.Interfaceの実装です。
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info はgormlogger.Interfaceの実装です。
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn はgormlogger.Interfaceの実装です。
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error はgormlogger.Interfaceの実装です。
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace はgormlogger.Interfaceの実装です。
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		level, msg = slog.LevelError, "query failed"
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		level, msg = slog.LevelWarn, "slow query"
+	case l.level >= gormlogger.Info:
+		level, msg = slog.LevelInfo, "query"
+	default:
+		return
+	}
+
+	logger := FromContext(ctx)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	sql = placeholderPattern.ReplaceAllString(sql, "$$$1")
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter はgorm.ParamsFilterの実装です。パラメーターを捨ててSQLをプレースホルダーのまま出力させます。
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader はリクエストIDのヘッダーです。
+const RequestIDHeader = "X-Request-ID"
+
+// 受け取ったリクエストIDは、ログを壊さない文字だけで構成されている場合に使用する
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// RequestID はコンテキストに格納されたリクエストIDを返します。
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Middleware はリクエストIDを割り当て、リクエストIDつきのロガーをリクエストのコンテキストに格納します。
+// トレースのスパンが開始されている場合はトレースIDもロガーに付けます。
+// X-Request-IDヘッダーがあればその値を使用し、なければ生成します。リクエストIDはレスポンスのヘッダーにも設定します。
+// リクエストの完了後にアクセスログを出力します（クエリ文字列はトークンを含むことがあるため出力しない）。
+func Middleware(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+
+		logger := base.With("request_id", id)
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			logger = logger.With("trace_id", sc.TraceID().String())
+		}
+		ctx := context.WithValue(c.Request.Context(), requestIDKey{}, id)
+		c.Request = c.Request.WithContext(WithLogger(ctx, logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)), // 本文を書き込んでいない場合は-1
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+// Recovery はハンドラーのpanicを回復し、スタックトレースをリクエストのロガーに出力します。
+// panicはgin.Context.Errorでエラーとして登録し、ステータスを500にして処理を中断します。
+// レスポンスの本文は外側のエラーを変換するミドルウェアが書き込みます。
+// gin.Recoveryと違い、リクエストのヘッダー（Cookieなど）は出力しません。
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				FromContext(c.Request.Context()).Error("panic recovered",
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				_ = c.Error(fmt.Errorf("panic: %v", r))
+				c.Abort()
+				c.Status(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand.Readはエラーを返さない
+	return hex.EncodeToString(b)
+}
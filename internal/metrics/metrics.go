@@ -0,0 +1,174 @@
+// Package metrics はPrometheus形式のメトリクスを提供します。
+//
+// メトリクスはこのパッケージのRegistryに登録し、Handlerで公開します。
+// HTTPリクエストはMiddleware、GORMのクエリはInstrumentGORMで計測し、
+// フラグの提出やログインなどのイベントはサービスからObserve関数を呼び出して記録します。
+//
+// 起動中の問題のコンテナ数のゲージはまだありません。問題のコンテナ（models.DockerChallenge）を起動・停止する
+// 仕組みがないため、値を取得する先がないからです。コンテナの管理を追加するときは、起動中のインスタンスを
+// 数えるGaugeFunc（ctfforge_active_container_instances）をここに登録してください。
+package metrics
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "ctfforge"
+
+// Registry はアプリケーションのメトリクスの登録先です。GoランタイムとプロセスのメトリクスもHandlerで公開します。
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	submissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_total",
+		Help:      "Flag submissions by result (correct or incorrect).",
+	}, []string{"result"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Successful logins by method (password, mfa or oauth) and OAuth provider.",
+	}, []string{"method", "provider"})
+)
+
+// ログイン方法
+const (
+	LoginPassword = "password" // メールアドレスとパスワード（二要素認証なし）
+	LoginMFA      = "mfa"      // パスワードと二要素認証
+	LoginOAuth    = "oauth"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbQueryDuration,
+		submissions,
+		logins,
+	)
+}
+
+// ObserveSubmission はフラグの提出を記録します。
+func ObserveSubmission(correct bool) {
+	result := "incorrect"
+	if correct {
+		result = "correct"
+	}
+	submissions.WithLabelValues(result).Inc()
+}
+
+// ObserveLogin はログインの成功を記録します。providerはOAuthの場合のみ指定します。
+func ObserveLogin(method, provider string) {
+	logins.WithLabelValues(method, provider).Inc()
+}
+
+// Middleware はHTTPリクエストの件数と処理時間を記録します。
+// ラベルにはパスそのものではなくルートのテンプレート（/api/challenges/:id など）を使用します。
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched" // 存在しないパスでラベルが増えないようにまとめる
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler はメトリクスを公開するハンドラーを返します。
+// bearerTokenを指定した場合は Authorization: Bearer <token> が一致するリクエストのみ許可します。
+func Handler(bearerToken string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	if bearerToken == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(bearerToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+const startTimeKey = "metrics:start_time"
+
+// InstrumentGORM はGORMのコールバックを登録してクエリの処理時間を記録します。
+func InstrumentGORM(db *gorm.DB) error {
+	start := func(tx *gorm.DB) {
+		tx.InstanceSet(startTimeKey, time.Now())
+	}
+	observe := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(startTimeKey)
+			if !ok {
+				return
+			}
+			started, ok := v.(time.Time)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			if table == "" {
+				table = "unknown" // Rawなどテーブルを特定できないクエリ
+			}
+			dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(started).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", start),
+		cb.Create().After("gorm:create").Register("metrics:after_create", observe("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", start),
+		cb.Query().After("gorm:query").Register("metrics:after_query", observe("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", start),
+		cb.Update().After("gorm:update").Register("metrics:after_update", observe("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", start),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", observe("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", start),
+		cb.Row().After("gorm:row").Register("metrics:after_row", observe("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", start),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", observe("raw")),
+	)
+}
@@ -0,0 +1,326 @@
+// Package migrate はバージョン付きのSQLマイグレーションを適用します。
+//
+// 適用済みのバージョンはschema_migrationsテーブルに記録します。各マイグレーションは
+// 記録の更新とあわせて1つのトランザクションで実行するため、失敗した場合は何も変更されません。
+// 複数のサーバーが同時に起動しても二重に適用されないよう、実行中はアドバイザリロックを取得します。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey はマイグレーション中に取得するアドバイザリロックのキーです。
+const lockKey int64 = 0x6d6967726174 // "migrat"
+
+var (
+	ErrUnknownVersion = errors.New("unknown migration version")
+	ErrMissingSource  = errors.New("applied migration is missing from source")
+)
+
+// Migration は1つのバージョンのマイグレーションです。
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String は「001_create_tables」の形式でマイグレーションを表します。
+func (m *Migration) String() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// Status はマイグレーションの適用状況です。
+type Status struct {
+	Migration *Migration
+	AppliedAt *time.Time // 未適用の場合はnil
+}
+
+// Load はfsysの「<バージョン>_<名前>.up.sql」と「<バージョン>_<名前>.down.sql」を読み込み、バージョン順に返します。
+// 組になっていないファイルやバージョンの重複はエラーになります。
+func Load(fsys fs.FS) ([]*Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, file := range names {
+		base := strings.TrimSuffix(file, ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+		version, err := strconv.ParseUint(versionStr, 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", file)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: name}
+			byVersion[m.Version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == ".up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %s must have both up and down files", m)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Runner はマイグレーションをデータベースに適用します。
+type Runner struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// NewRunner はRunnerのコンストラクタです。migrationsはバージョン順である必要があります（Loadの戻り値）。
+func NewRunner(db *sql.DB, migrations []*Migration) *Runner {
+	return &Runner{db: db, migrations: migrations}
+}
+
+// Up は未適用のマイグレーションを古い順に最大n個適用し、適用したものを返します。nが0以下の場合はすべて適用します。
+func (r *Runner) Up(ctx context.Context, n int) ([]*Migration, error) {
+	var applied []*Migration
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		applied, err = up(ctx, conn, r.migrations, n)
+		return err
+	})
+	return applied, err
+}
+
+// Down は適用済みのマイグレーションを新しい順に最大n個取り消し、取り消したものを返します。nが0以下の場合はすべて取り消します。
+func (r *Runner) Down(ctx context.Context, n int) ([]*Migration, error) {
+	var reverted []*Migration
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		reverted, err = down(ctx, conn, r.migrations, n)
+		return err
+	})
+	return reverted, err
+}
+
+// Status はすべてのマイグレーションの適用状況をバージョン順に返します。
+func (r *Runner) Status(ctx context.Context) ([]*Status, error) {
+	var res []*Status
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn, r.migrations)
+		if err != nil {
+			return err
+		}
+		res = make([]*Status, len(r.migrations))
+		for i, m := range r.migrations {
+			res[i] = &Status{Migration: m}
+			if at, ok := applied[m.Version]; ok {
+				res[i].AppliedAt = &at
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Force はマイグレーションを実行せずに、version以下を適用済み・versionより新しいものを未適用として記録します。
+// 手動で作成した既存のデータベースを管理下に置く場合や、手動で修正した後に使用します。versionが0の場合はすべて未適用にします。
+func (r *Runner) Force(ctx context.Context, version uint) error {
+	if version != 0 && r.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		if err := ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version > $1", version); err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if m.Version > version {
+				break
+			}
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING",
+				m.Version, m.Name,
+			); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+func (r *Runner) find(version uint) *Migration {
+	for _, m := range r.migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	return nil
+}
+
+// withLock は専用の接続でアドバイザリロックを取得してfnを実行します。
+// アドバイザリロックはセッション単位のため、ロックの取得から解放まで同じ接続を使用します。
+func (r *Runner) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	// ctxがキャンセルされていても解放できるよう、解放には別のコンテキストを使用する
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	return fn(conn)
+}
+
+func up(ctx context.Context, conn *sql.Conn, migrations []*Migration, n int) ([]*Migration, error) {
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, conn, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Migration
+	for _, m := range migrations {
+		if n > 0 && len(res) >= n {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := run(ctx, conn, m.Up,
+			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name,
+		); err != nil {
+			return res, fmt.Errorf("migration %s failed: %w", m, err)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func down(ctx context.Context, conn *sql.Conn, migrations []*Migration, n int) ([]*Migration, error) {
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, conn, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if n > 0 && len(res) >= n {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := run(ctx, conn, m.Down,
+			"DELETE FROM schema_migrations WHERE version = $1", m.Version,
+		); err != nil {
+			return res, fmt.Errorf("rollback of migration %s failed: %w", m, err)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// run はマイグレーションのSQLと記録の更新を1つのトランザクションで実行します。
+func run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`)
+	return err
+}
+
+// appliedVersions は適用済みのバージョンと適用日時を返します。
+// ソースに存在しないバージョンが適用済みの場合は、別のバージョンのバイナリで適用された可能性があるためエラーにします。
+func appliedVersions(ctx context.Context, conn *sql.Conn, migrations []*Migration) (map[uint]time.Time, error) {
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[uint]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+
+	applied := make(map[uint]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		if !known[uint(version)] {
+			return nil, fmt.Errorf("%w: %d", ErrMissingSource, version)
+		}
+		applied[uint(version)] = at
+	}
+	return applied, rows.Err()
+}
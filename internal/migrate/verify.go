@@ -0,0 +1,239 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Verify は一時的なスキーマにすべてのマイグレーションを適用・取り消し・再適用し、
+// 作成されたテーブルがモデルと一致しているかを確認します。見つかった差分を返します（一致している場合は空）。
+//
+// 確認する内容は次のとおりです。
+//   - すべてのdownでテーブルが残らないこと
+//   - モデルとテーブルが1対1で対応し、カラムに過不足がないこと
+//   - NOT NULLのフィールドのカラムがNULLを許可していないこと、ポインタのフィールドのカラムがNULLを許可していること
+//   - unique・uniqueIndex・indexのタグに対応するインデックス（一意制約を含む）があること
+//
+// 一時的なスキーマは確認後に削除するため、既存のテーブルには影響しません。
+func Verify(ctx context.Context, db *sql.DB, migrations []*Migration, models []any) ([]string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	scratch := fmt.Sprintf("migrate_verify_%d", time.Now().UnixNano())
+	if _, err := conn.ExecContext(ctx, "CREATE SCHEMA "+scratch); err != nil {
+		return nil, err
+	}
+	defer func() {
+		cleanupCtx := context.WithoutCancel(ctx)
+		conn.ExecContext(cleanupCtx, "RESET search_path")
+		conn.ExecContext(cleanupCtx, "DROP SCHEMA "+scratch+" CASCADE")
+	}()
+	if _, err := conn.ExecContext(ctx, "SET search_path TO "+scratch); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	if _, err := up(ctx, conn, migrations, 0); err != nil {
+		return nil, err
+	}
+	if _, err := down(ctx, conn, migrations, 0); err != nil {
+		return nil, err
+	}
+	tables, err := listTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		diffs = append(diffs, fmt.Sprintf("table %s remains after all down migrations", table))
+	}
+	if _, err := up(ctx, conn, migrations, 0); err != nil {
+		return nil, fmt.Errorf("re-applying after down: %w", err)
+	}
+
+	tables, err = listTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		remaining[table] = true
+	}
+
+	cache := &sync.Map{}
+	naming := schema.NamingStrategy{IdentifierMaxLength: 64} // gorm.Configの既定値
+	for _, model := range models {
+		sch, err := schema.Parse(model, cache, naming)
+		if err != nil {
+			return nil, err
+		}
+		if !remaining[sch.Table] {
+			diffs = append(diffs, fmt.Sprintf("table %s (%s) is not created by migrations", sch.Table, sch.Name))
+			continue
+		}
+		delete(remaining, sch.Table)
+
+		tableDiffs, err := verifyTable(ctx, conn, sch)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, tableDiffs...)
+	}
+	for table := range remaining {
+		diffs = append(diffs, fmt.Sprintf("table %s has no model", table))
+	}
+
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+type indexInfo struct {
+	unique  bool
+	columns string // 並べ替えてカンマで連結したカラム名
+}
+
+func verifyTable(ctx context.Context, conn *sql.Conn, sch *schema.Schema) ([]string, error) {
+	columns, err := listColumns(ctx, conn, sch.Table)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := listIndexes(ctx, conn, sch.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	for _, name := range sch.DBNames {
+		field := sch.FieldsByDBName[name]
+		nullable, ok := columns[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("column %s.%s is missing", sch.Table, name))
+			continue
+		}
+		delete(columns, name)
+
+		if (field.NotNull || field.PrimaryKey) && nullable {
+			diffs = append(diffs, fmt.Sprintf("column %s.%s is nullable but the model requires NOT NULL", sch.Table, name))
+		}
+		if field.FieldType.Kind() == reflect.Ptr && !nullable {
+			diffs = append(diffs, fmt.Sprintf("column %s.%s is NOT NULL but the model field is a pointer", sch.Table, name))
+		}
+		if field.Unique && !hasIndex(indexes, []string{name}, true) {
+			diffs = append(diffs, fmt.Sprintf("column %s.%s is missing a unique constraint", sch.Table, name))
+		}
+	}
+	for name := range columns {
+		diffs = append(diffs, fmt.Sprintf("column %s.%s has no model field", sch.Table, name))
+	}
+
+	for _, idx := range sch.ParseIndexes() {
+		names := make([]string, len(idx.Fields))
+		for i, f := range idx.Fields {
+			names[i] = f.DBName
+		}
+		unique := idx.Class == "UNIQUE"
+		if !hasIndex(indexes, names, unique) {
+			kind := "index"
+			if unique {
+				kind = "unique index"
+			}
+			diffs = append(diffs, fmt.Sprintf("%s %s on %s(%s) is missing", kind, idx.Name, sch.Table, strings.Join(names, ", ")))
+		}
+	}
+	return diffs, nil
+}
+
+// hasIndex はcolumnsと同じカラムの組のインデックスがあるかを返します。uniqueの場合は一意のインデックスに限ります。
+func hasIndex(indexes []indexInfo, columns []string, unique bool) bool {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	key := strings.Join(sorted, ",")
+	for _, idx := range indexes {
+		if idx.columns == key && (idx.unique || !unique) {
+			return true
+		}
+	}
+	return false
+}
+
+func listTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' AND table_name <> 'schema_migrations'
+ORDER BY table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// listColumns はカラム名とNULLを許可するかどうかを返します。
+func listColumns(ctx context.Context, conn *sql.Conn, table string) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT column_name, is_nullable = 'YES' FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = $1`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var nullable bool
+		if err := rows.Scan(&name, &nullable); err != nil {
+			return nil, err
+		}
+		columns[name] = nullable
+	}
+	return columns, rows.Err()
+}
+
+// listIndexes は部分インデックスを除くインデックス（一意制約・主キーを含む）を返します。
+func listIndexes(ctx context.Context, conn *sql.Conn, table string) ([]indexInfo, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT i.indisunique,
+  ARRAY_TO_STRING(ARRAY(
+    SELECT a.attname FROM pg_attribute a
+    WHERE a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+  ), ',')
+FROM pg_index i
+JOIN pg_class t ON t.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+WHERE n.nspname = current_schema() AND t.relname = $1 AND i.indpred IS NULL`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []indexInfo
+	for rows.Next() {
+		var idx indexInfo
+		var columns string
+		if err := rows.Scan(&idx.unique, &columns); err != nil {
+			return nil, err
+		}
+		names := strings.Split(columns, ",")
+		sort.Strings(names)
+		idx.columns = strings.Join(names, ",")
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
@@ -0,0 +1,59 @@
+package migrate_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/migrate"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/migrations"
+)
+
+// TestMigrationsMatchModels はTEST_DATABASE_URLのデータベースにすべてのマイグレーションを適用し、
+// Verifyでマイグレーションとモデルが一致していることを確認します。
+// TEST_DATABASE_URLが未設定の場合はスキップします。テスト専用のデータベースを指定してください。
+func TestMigrationsMatchModels(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	ms, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	db, err := config.OpenDB(config.DatabaseConfig{URL: dsn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	runner := migrate.NewRunner(sqlDB, ms)
+	if _, err := runner.Up(ctx, 0); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			t.Errorf("%s is not applied after Up", st.Migration)
+		}
+	}
+
+	diffs, err := migrate.Verify(ctx, sqlDB, ms, models.All())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	for _, diff := range diffs {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIToken はCLIや自動化スクリプト向けのパーソナルアクセストークンです。
+// トークン自体は保存せず、ハッシュと表示用のプレフィックスのみを保存します。
+type APIToken struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"not null;index"`
+	User        User   `gorm:"foreignKey:UserID"`
+	Name        string `gorm:"not null"`
+	TokenPrefix string `gorm:"not null"`             // 一覧表示でトークンを見分けるための先頭部分
+	TokenHash   string `gorm:"not null;uniqueIndex"` // SHA-256
+	Scopes      string `gorm:"not null"`             // スペース区切りのスコープ
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// ScopeList はスコープをスライスで返します。
+func (t *APIToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsActive は失効しておらず、有効期限内かどうかを判定します。
+func (t *APIToken) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
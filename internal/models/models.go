@@ -0,0 +1,22 @@
+package models
+
+// All はすべてのモデルを返します。マイグレーションとモデルの整合性の確認（migrate verify）に使用します。
+// モデルを追加した場合はここにも追加してください。
+func All() []any {
+	return []any{
+		&User{},
+		&ChallengeCategory{},
+		&Challenge{},
+		&ChallengeFile{},
+		&DockerChallenge{},
+		&Submission{},
+		&OAuthAccount{},
+		&RefreshToken{},
+		&PasswordResetToken{},
+		&RecoveryCode{},
+		&APIToken{},
+		&OAuthExchangeCode{},
+		&PendingOAuthSignup{},
+		&UserAchievement{},
+	}
+}
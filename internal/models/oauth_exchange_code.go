@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// OAuthExchangeCode はOAuthログイン後にフロントエンドへ渡す使い捨ての認可コードです。
+// コード自体は保存せず、ハッシュのみを保存します。トークンは交換時に発行します。
+type OAuthExchangeCode struct {
+	ID            uint      `gorm:"primaryKey"`
+	UserID        uint      `gorm:"not null;index"`
+	User          User      `gorm:"foreignKey:UserID"`
+	Provider      string    `gorm:"not null"`             // 認証したOAuthプロバイダー
+	CodeHash      string    `gorm:"not null;uniqueIndex"` // SHA-256
+	RedirectURI   string    `gorm:"not null"`
+	CodeChallenge string    // PKCE（S256）。空の場合はcode_verifierを検証しない
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (OAuthExchangeCode) TableName() string {
+	return "oauth_exchange_codes"
+}
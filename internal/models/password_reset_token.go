@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken はパスワードリセット用の使い捨てトークンです。トークン自体は保存せず、ハッシュのみを保存します。
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"foreignKey:UserID"`
+	TokenHash string    `gorm:"not null;uniqueIndex"` // SHA-256
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PendingOAuthSignup はユーザー名の選択を待っているOAuthの新規登録です。
+// 登録を完了するまでユーザーとOAuthAccountは作成しません。トークン自体は保存せず、ハッシュのみを保存します。
+type PendingOAuthSignup struct {
+	ID                uint   `gorm:"primaryKey"`
+	TokenHash         string `gorm:"not null;uniqueIndex"` // SHA-256
+	Provider          string `gorm:"not null"`
+	ProviderUserID    string `gorm:"not null"`
+	Email             string
+	SuggestedUsername string `gorm:"not null"`
+	AccessToken       string
+	RefreshToken      string
+	TokenExpiry       time.Time
+	CodeChallenge     string    // リダイレクトでPKCEを使用した場合のcode_challenge
+	ExpiresAt         time.Time `gorm:"not null"`
+	UsedAt            *time.Time
+	CreatedAt         time.Time
+}
+
+func (PendingOAuthSignup) TableName() string {
+	return "pending_oauth_signups"
+}
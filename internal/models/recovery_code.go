@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RecoveryCode は二要素認証のリカバリーコードです。コード自体は保存せず、ハッシュのみを保存します。
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	CodeHash  string `gorm:"not null"` // SHA-256
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RefreshToken は発行済みリフレッシュトークンの記録です。
+// 同じログインから派生したトークンは同じFamilyIDを持ち、FamilyIDがログインセッションのIDになります。
+type RefreshToken struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	User       User   `gorm:"foreignKey:UserID"`
+	JTI        string `gorm:"column:jti;not null;uniqueIndex"` // JWTのjtiクレーム
+	FamilyID   string `gorm:"not null;index"`
+	ReplacedBy string // ローテーション後のトークンのjti
+	UserAgent  string // 発行・更新時のクライアント情報
+	IPAddress  string
+	SignedInAt time.Time `gorm:"not null"` // セッション開始（ログイン）日時
+	LastUsedAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// IsRevoked は失効済み（ローテーション済みを含む）かどうかを判定します。
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
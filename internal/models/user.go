@@ -2,10 +2,52 @@ package models
 
 import "time"
 
+// ユーザーロール
+const (
+	RoleUser  = "user"  // 一般ユーザー
+	RoleAdmin = "admin" // 管理者
+)
+
+// ユーザーステータス
+const (
+	UserStatusActive = "active" // 通常
+	UserStatusBanned = "banned" // BAN済み（ログイン・トークン更新・フラグ提出不可）
+)
+
 type User struct {
-	ID           uint   `gorm:"primaryKey"`
-	Username     string `gorm:"unique;not null"`
-	Email        string `gorm:"unique;not null"`
-	PasswordHash string
-	CreatedAt    time.Time
+	ID                    uint   `gorm:"primaryKey"`
+	Username              string `gorm:"unique;not null"`
+	Email                 string `gorm:"not null"` // OAuthで取得できない場合は空。空でない値だけ一意（部分インデックスidx_users_email）
+	PasswordHash          string
+	Role                  string `gorm:"not null;default:user"`
+	Status                string `gorm:"not null;default:active"`
+	BanReason             string
+	BannedAt              *time.Time
+	PasswordResetRequired bool `gorm:"not null;default:false"`
+	EmailVerified         bool `gorm:"not null;default:false"`
+	EmailVerifiedAt       *time.Time
+	VerificationSentAt    *time.Time // 確認メールの最終送信日時（再送の制限に使用）
+	TOTPSecret            string     `gorm:"column:totp_secret"` // 二要素認証のシークレット（base32）。有効化前は登録中のシークレット
+	TOTPEnabled           bool       `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPEnabledAt         *time.Time `gorm:"column:totp_enabled_at"`
+	TOTPLastUsedStep      int64      `gorm:"column:totp_last_used_step;not null;default:0"`  // 同じコードの再利用防止
+	TOTPFailedAttempts    int        `gorm:"column:totp_failed_attempts;not null;default:0"` // 続けてコードを誤った回数
+	TOTPLockedUntil       *time.Time `gorm:"column:totp_locked_until"`                       // コードの誤りによるロックの期限
+	DisplayName           string     // 表示名
+	Bio                   string     // 自己紹介
+	AvatarURL             string     // アイコン画像のURL
+	UsernameChangedAt     *time.Time // ユーザー名の最終変更日時（変更間隔の制限に使用）
+	PendingEmail          string     // 確認待ちの新しいメールアドレス
+	HideSolves            bool       `gorm:"not null;default:false"` // 公開プロフィールで解いた問題を非表示にする
+	CreatedAt             time.Time
+}
+
+// IsAdmin は管理者ロールかどうかを判定します。
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsBanned はBANされているかどうかを判定します。
+func (u *User) IsBanned() bool {
+	return u.Status == UserStatusBanned
 }
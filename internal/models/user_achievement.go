@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserAchievement はユーザーが解除した実績です。実績の定義はachievementパッケージのルールにあります。
+type UserAchievement struct {
+	ID            uint   `gorm:"primaryKey"`
+	UserID        uint   `gorm:"not null;uniqueIndex:idx_user_achievements_user_achievement"`
+	AchievementID string `gorm:"not null;uniqueIndex:idx_user_achievements_user_achievement"`
+	UnlockedAt    time.Time
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementRepository はユーザーが解除した実績に関するDB操作インターフェースです。
+type AchievementRepository interface {
+	ListByUserID(ctx context.Context, userID uint) ([]*models.UserAchievement, error)
+	Unlock(ctx context.Context, userID uint, achievementID string, unlockedAt time.Time) (bool, error)
+}
+
+type achievementRepo struct {
+	db *gorm.DB
+}
+
+// NewAchievementRepository はachievementRepoのコンストラクタです。
+func NewAchievementRepository(db *gorm.DB) AchievementRepository {
+	return &achievementRepo{db: db}
+}
+
+// ListByUserID はユーザーが解除した実績を解除日時の古い順に取得します。
+func (r *achievementRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.UserAchievement, error) {
+	var achievements []*models.UserAchievement
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("unlocked_at, id").Find(&achievements).Error
+	if err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// Unlock は実績を解除済みにします。既に解除済みの場合は何もせずfalseを返します。
+func (r *achievementRepo) Unlock(ctx context.Context, userID uint, achievementID string, unlockedAt time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserAchievement{
+		UserID:        userID,
+		AchievementID: achievementID,
+		UnlockedAt:    unlockedAt,
+	})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrAPITokenNotFound は対象のAPIトークンが存在しない（または他ユーザーのもの・失効済み）場合のエラーです。
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+// APITokenRepository はパーソナルアクセストークンに関するDB操作インターフェースです。
+type APITokenRepository interface {
+	Create(ctx context.Context, t *models.APIToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	ListActiveByUserID(ctx context.Context, userID uint) ([]*models.APIToken, error)
+	Revoke(ctx context.Context, userID, tokenID uint) error
+	TouchLastUsed(ctx context.Context, tokenID uint, usedAt time.Time) error
+}
+
+type apiTokenRepo struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository はapiTokenRepoのコンストラクタです。
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepo{db: db}
+}
+
+func (r *apiTokenRepo) Create(ctx context.Context, t *models.APIToken) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+// GetByTokenHash はハッシュからトークンを取得します。所有ユーザーも読み込みます。存在しない場合はnilを返します。
+func (r *apiTokenRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	var t models.APIToken
+	err := r.db.WithContext(ctx).Preload("User").Where("token_hash = ?", tokenHash).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListActiveByUserID はユーザーの失効していない・有効期限内のトークンを作成日時の新しい順に返します。
+func (r *apiTokenRepo) ListActiveByUserID(ctx context.Context, userID uint) ([]*models.APIToken, error) {
+	var tokens []*models.APIToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke はユーザー自身のトークンを失効させます。該当するトークンがない場合はErrAPITokenNotFoundを返します。
+func (r *apiTokenRepo) Revoke(ctx context.Context, userID, tokenID uint) error {
+	res := r.db.WithContext(ctx).Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func (r *apiTokenRepo) TouchLastUsed(ctx context.Context, tokenID uint, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIToken{}).
+		Where("id = ?", tokenID).
+		Update("last_used_at", usedAt).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"gorm.io/gorm"
@@ -19,8 +20,38 @@ type ChallengeRepository interface {
 	GetAllPublic(ctx context.Context) ([]*models.Challenge, error)
 	IsSolved(ctx context.Context, challengeID uint, userID uint) (bool, error)
 	CreateSubmission(ctx context.Context, submission *models.Submission) error
+	CollectSubmissionsByUserID(ctx context.Context, userID uint) ([]*models.Submission, error)
+	CollectPublicByUserID(ctx context.Context, userID uint) ([]*models.Challenge, error)
+	CollectSolvesByUserID(ctx context.Context, userID uint) ([]*SolveRecord, error)
+	CountPublicByCategory(ctx context.Context) ([]*CategoryCount, error)
+	CountUsersWithScoreAbove(ctx context.Context, score int) (int64, error)
+	GetFirstSolverID(ctx context.Context, challengeID uint) (uint, error)
 }
 
+// SolveRecord はユーザーが解いた公開問題と最初に正解した日時です。
+type SolveRecord struct {
+	ChallengeID uint
+	Title       string
+	Category    string
+	Score       int
+	SolvedAt    time.Time
+}
+
+// CategoryCount はカテゴリーごとの公開問題数です。
+type CategoryCount struct {
+	Category string
+	Count    int64
+}
+
+// scoreboardQuery は有効なユーザーごとの合計スコアを求めるサブクエリです。
+// 同じ問題への複数回の正解は1回として数え、公開中の問題のみを対象とします。
+const scoreboardQuery = `
+SELECT s.user_id, SUM(c.score) AS score
+FROM (SELECT DISTINCT user_id, challenge_id FROM submissions WHERE is_correct = TRUE) s
+JOIN challenges c ON c.id = s.challenge_id AND c.is_public = TRUE
+JOIN users u ON u.id = s.user_id AND u.status = 'active'
+GROUP BY s.user_id`
+
 type challengeRepo struct {
 	db *gorm.DB
 }
@@ -104,3 +135,88 @@ func (r *challengeRepo) IsSolved(ctx context.Context, challengeID uint, userID u
 func (r *challengeRepo) CreateSubmission(ctx context.Context, submission *models.Submission) error {
 	return r.db.WithContext(ctx).Create(submission).Error
 }
+
+// CollectSubmissionsByUserIDは、指定されたユーザーの提出履歴を新しい順にすべて取得します。
+func (r *challengeRepo) CollectSubmissionsByUserID(ctx context.Context, userID uint) ([]*models.Submission, error) {
+	var submissions []*models.Submission
+	err := r.db.WithContext(ctx).Preload("Challenge").Where("user_id = ?", userID).Order("submitted_at DESC").Find(&submissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// CollectPublicByUserIDは、指定されたユーザーが作成した公開中の問題を新しい順に取得します。
+func (r *challengeRepo) CollectPublicByUserID(ctx context.Context, userID uint) ([]*models.Challenge, error) {
+	var challenges []*models.Challenge
+	err := r.db.WithContext(ctx).Preload("Category").
+		Where("user_id = ? AND is_public = ?", userID, true).
+		Order("created_at DESC").
+		Find(&challenges).Error
+	if err != nil {
+		return nil, err
+	}
+	return challenges, nil
+}
+
+// CollectSolvesByUserIDは、指定されたユーザーが解いた公開問題を、最初に正解した日時の新しい順に取得します。
+func (r *challengeRepo) CollectSolvesByUserID(ctx context.Context, userID uint) ([]*SolveRecord, error) {
+	var solves []*SolveRecord
+	err := r.db.WithContext(ctx).Raw(`
+SELECT c.id AS challenge_id, c.title, COALESCE(cc.name, '') AS category, c.score, MIN(s.submitted_at) AS solved_at
+FROM submissions s
+JOIN challenges c ON c.id = s.challenge_id
+LEFT JOIN challenge_categories cc ON cc.id = c.category_id
+WHERE s.user_id = ? AND s.is_correct = TRUE AND c.is_public = TRUE
+GROUP BY c.id, c.title, cc.name, c.score
+ORDER BY solved_at DESC`, userID).Scan(&solves).Error
+	if err != nil {
+		return nil, err
+	}
+	return solves, nil
+}
+
+// CountPublicByCategoryは、カテゴリーごとの公開問題数を取得します。カテゴリー未設定の問題は空文字列のカテゴリーとして数えます。
+func (r *challengeRepo) CountPublicByCategory(ctx context.Context) ([]*CategoryCount, error) {
+	var counts []*CategoryCount
+	err := r.db.WithContext(ctx).Raw(`
+SELECT COALESCE(cc.name, '') AS category, COUNT(*) AS count
+FROM challenges c
+LEFT JOIN challenge_categories cc ON cc.id = c.category_id
+WHERE c.is_public = TRUE
+GROUP BY cc.name
+ORDER BY category`).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountUsersWithScoreAboveは、合計スコアが指定したスコアより高いユーザーの数を取得します。順位の計算に使用します。
+func (r *challengeRepo) CountUsersWithScoreAbove(ctx context.Context, score int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM (`+scoreboardQuery+`) sb WHERE sb.score > ?`, score).Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetFirstSolverIDは、問題の作成者以外で最初に正解したユーザーのIDを取得します。正解者がいない場合は0を返します。
+func (r *challengeRepo) GetFirstSolverID(ctx context.Context, challengeID uint) (uint, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).Raw(`
+SELECT s.user_id
+FROM submissions s
+JOIN challenges c ON c.id = s.challenge_id
+WHERE s.challenge_id = ? AND s.is_correct = TRUE AND s.user_id <> c.user_id
+ORDER BY s.submitted_at, s.id
+LIMIT 1`, challengeID).Scan(&userIDs).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	return userIDs[0], nil
+}
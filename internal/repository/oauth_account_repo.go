@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -9,12 +10,17 @@ import (
 )
 
 type OAuthAccountRepository interface {
-	FindByProviderAndProviderUserID(provider string, providerUserID string) (*models.OAuthAccount, error)
-	Create(account *models.OAuthAccount) error
-	FindOrCreate(account *models.OAuthAccount) (*models.OAuthAccount, error)
-	UpdateTokenInfo(accountID uint, accessToken, refreshToken string, tokenExpiry time.Time) error
+	FindByProviderAndProviderUserID(ctx context.Context, provider string, providerUserID string) (*models.OAuthAccount, error)
+	Create(ctx context.Context, account *models.OAuthAccount) error
+	FindOrCreate(ctx context.Context, account *models.OAuthAccount) (*models.OAuthAccount, error)
+	UpdateTokenInfo(ctx context.Context, accountID uint, accessToken, refreshToken string, tokenExpiry time.Time) error
+	ListByUserID(ctx context.Context, userID uint) ([]*models.OAuthAccount, error)
+	DeleteByUserID(ctx context.Context, userID, accountID uint) error
 }
 
+// ErrOAuthAccountNotFound は対象のOAuth連携が存在しない（または他ユーザーのもの）場合のエラーです。
+var ErrOAuthAccountNotFound = errors.New("oauth account not found")
+
 type oauthRepo struct {
 	db *gorm.DB
 }
@@ -23,21 +29,21 @@ func NewOAuthAccountRepository(db *gorm.DB) OAuthAccountRepository {
 	return &oauthRepo{db: db}
 }
 
-func (r *oauthRepo) FindByProviderAndProviderUserID(provider string, providerUserID string) (*models.OAuthAccount, error) {
+func (r *oauthRepo) FindByProviderAndProviderUserID(ctx context.Context, provider string, providerUserID string) (*models.OAuthAccount, error) {
 	var account models.OAuthAccount
-	if err := r.db.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&account).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&account).Error; err != nil {
 		return nil, err
 	}
 	return &account, nil
 }
 
-func (r *oauthRepo) Create(account *models.OAuthAccount) error {
-	return r.db.Create(account).Error
+func (r *oauthRepo) Create(ctx context.Context, account *models.OAuthAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
 }
 
-func (r *oauthRepo) FindOrCreate(account *models.OAuthAccount) (*models.OAuthAccount, error) {
+func (r *oauthRepo) FindOrCreate(ctx context.Context, account *models.OAuthAccount) (*models.OAuthAccount, error) {
 	var existing models.OAuthAccount
-	err := r.db.
+	err := r.db.WithContext(ctx).
 		Where("provider = ? AND provider_user_id = ?", account.Provider, account.ProviderUserID).
 		First(&existing).Error
 
@@ -45,7 +51,7 @@ func (r *oauthRepo) FindOrCreate(account *models.OAuthAccount) (*models.OAuthAcc
 		return &existing, nil // 見つかったのでそれを返す
 	}
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		if err := r.db.Create(account).Error; err != nil {
+		if err := r.db.WithContext(ctx).Create(account).Error; err != nil {
 			return nil, err
 		}
 		return account, nil
@@ -53,8 +59,8 @@ func (r *oauthRepo) FindOrCreate(account *models.OAuthAccount) (*models.OAuthAcc
 	return nil, err
 }
 
-func (r *oauthRepo) UpdateTokenInfo(accountID uint, accessToken, refreshToken string, tokenExpiry time.Time) error {
-	return r.db.Model(&models.OAuthAccount{}).
+func (r *oauthRepo) UpdateTokenInfo(ctx context.Context, accountID uint, accessToken, refreshToken string, tokenExpiry time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OAuthAccount{}).
 		Where("id = ?", accountID).
 		Updates(map[string]interface{}{
 			"access_token":  accessToken,
@@ -62,3 +68,22 @@ func (r *oauthRepo) UpdateTokenInfo(accountID uint, accessToken, refreshToken st
 			"token_expiry":  tokenExpiry,
 		}).Error
 }
+
+// ListByUserID はユーザーに連携済みのOAuthアカウントを連携日時の古い順に返します。
+func (r *oauthRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.OAuthAccount, error) {
+	var accounts []*models.OAuthAccount
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// DeleteByUserID はユーザー自身のOAuth連携を削除します。該当する連携がない場合はErrOAuthAccountNotFoundを返します。
+func (r *oauthRepo) DeleteByUserID(ctx context.Context, userID, accountID uint) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", accountID, userID).Delete(&models.OAuthAccount{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOAuthAccountNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrExchangeCodeNotFound は未使用・有効期限内の認可コードが見つからない場合のエラーです。
+var ErrExchangeCodeNotFound = errors.New("exchange code not found")
+
+// OAuthExchangeCodeRepository はOAuthログイン後の認可コードに関するDB操作インターフェースです。
+type OAuthExchangeCodeRepository interface {
+	Create(ctx context.Context, code *models.OAuthExchangeCode) error
+	Consume(ctx context.Context, codeHash string) (*models.OAuthExchangeCode, error)
+}
+
+type oauthExchangeCodeRepo struct {
+	db *gorm.DB
+}
+
+// NewOAuthExchangeCodeRepository はoauthExchangeCodeRepoのコンストラクタです。
+func NewOAuthExchangeCodeRepository(db *gorm.DB) OAuthExchangeCodeRepository {
+	return &oauthExchangeCodeRepo{db: db}
+}
+
+func (r *oauthExchangeCodeRepo) Create(ctx context.Context, code *models.OAuthExchangeCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+// Consume は未使用・有効期限内の認可コードを使用済みにして返します。
+// 同じコードの同時使用に備えて、使用済みへの更新は条件付きで行います。
+func (r *oauthExchangeCodeRepo) Consume(ctx context.Context, codeHash string) (*models.OAuthExchangeCode, error) {
+	var code models.OAuthExchangeCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, now).First(&code).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrExchangeCodeNotFound
+			}
+			return err
+		}
+
+		res := tx.Model(&models.OAuthExchangeCode{}).
+			Where("id = ? AND used_at IS NULL", code.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrExchangeCodeNotFound
+		}
+		code.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrResetTokenAlreadyUsed は使用済みのリセットトークンを使用しようとした場合のエラーです。
+var ErrResetTokenAlreadyUsed = errors.New("reset token already used")
+
+// PasswordResetTokenRepository はパスワードリセットトークンに関するDB操作インターフェースです。
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uint) error
+	InvalidateByUserID(ctx context.Context, userID uint) error
+	ExistsActiveCreatedSince(ctx context.Context, userID uint, since time.Time) (bool, error)
+}
+
+type passwordResetRepo struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository はpasswordResetRepoのコンストラクタです。
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetRepo{db: db}
+}
+
+func (r *passwordResetRepo) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *passwordResetRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed はトークンを使用済みにします。既に使用済みの場合はErrResetTokenAlreadyUsedを返します。
+func (r *passwordResetRepo) MarkUsed(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrResetTokenAlreadyUsed
+	}
+	return nil
+}
+
+// InvalidateByUserID はユーザーの未使用のトークンをすべて使用済みにします。
+func (r *passwordResetRepo) InvalidateByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+// ExistsActiveCreatedSince はsince以降に発行した未使用・有効期限内のトークンがあるかどうかを返します。
+func (r *passwordResetRepo) ExistsActiveCreatedSince(ctx context.Context, userID uint, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL AND expires_at > ? AND created_at >= ?", userID, time.Now(), since).
+		Count(&count).Error
+	return count > 0, err
+}
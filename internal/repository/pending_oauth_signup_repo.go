@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrPendingSignupNotFound は未使用・有効期限内の登録待ちが見つからない場合のエラーです。
+var ErrPendingSignupNotFound = errors.New("pending signup not found")
+
+// PendingOAuthSignupRepository はユーザー名の選択を待っているOAuthの新規登録に関するDB操作インターフェースです。
+type PendingOAuthSignupRepository interface {
+	Create(ctx context.Context, signup *models.PendingOAuthSignup) error
+	GetActiveByTokenHash(ctx context.Context, tokenHash string) (*models.PendingOAuthSignup, error)
+	MarkUsed(ctx context.Context, id uint) error
+}
+
+type pendingOAuthSignupRepo struct {
+	db *gorm.DB
+}
+
+// NewPendingOAuthSignupRepository はpendingOAuthSignupRepoのコンストラクタです。
+func NewPendingOAuthSignupRepository(db *gorm.DB) PendingOAuthSignupRepository {
+	return &pendingOAuthSignupRepo{db: db}
+}
+
+func (r *pendingOAuthSignupRepo) Create(ctx context.Context, signup *models.PendingOAuthSignup) error {
+	return r.db.WithContext(ctx).Create(signup).Error
+}
+
+// GetActiveByTokenHash は未使用・有効期限内の登録待ちを取得します。存在しない場合はErrPendingSignupNotFoundを返します。
+func (r *pendingOAuthSignupRepo) GetActiveByTokenHash(ctx context.Context, tokenHash string) (*models.PendingOAuthSignup, error) {
+	var signup models.PendingOAuthSignup
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&signup).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPendingSignupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &signup, nil
+}
+
+// MarkUsed は登録待ちを使用済みにします。既に使用済みの場合はErrPendingSignupNotFoundを返します。
+func (r *pendingOAuthSignupRepo) MarkUsed(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Model(&models.PendingOAuthSignup{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPendingSignupNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrRecoveryCodeNotFound は未使用のリカバリーコードが見つからない場合のエラーです。
+var ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+// RecoveryCodeRepository は二要素認証のリカバリーコードに関するDB操作インターフェースです。
+type RecoveryCodeRepository interface {
+	ReplaceAll(ctx context.Context, userID uint, codeHashes []string) error
+	Use(ctx context.Context, userID uint, codeHash string) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository はrecoveryCodeRepoのコンストラクタです。
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepo{db: db}
+}
+
+// ReplaceAll はユーザーのリカバリーコードをすべて削除し、新しいコードで置き換えます。
+func (r *recoveryCodeRepo) ReplaceAll(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]*models.RecoveryCode, len(codeHashes))
+		for i, h := range codeHashes {
+			codes[i] = &models.RecoveryCode{UserID: userID, CodeHash: h}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use は未使用のリカバリーコードを使用済みにします。該当するコードがない場合はErrRecoveryCodeNotFoundを返します。
+func (r *recoveryCodeRepo) Use(ctx context.Context, userID uint, codeHash string) error {
+	res := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepo) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRefreshTokenAlreadyRotated は既にローテーション・失効済みのトークンを更新しようとした場合のエラーです。
+	ErrRefreshTokenAlreadyRotated = errors.New("refresh token already rotated")
+	// ErrSessionNotFound は指定したユーザーの有効なセッションが存在しない場合のエラーです。
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// RefreshTokenRepository はリフレッシュトークンに関するDB操作インターフェースです。
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByJTI(ctx context.Context, jti string) (*models.RefreshToken, error)
+	Rotate(ctx context.Context, oldJTI string, next *models.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllByUserID(ctx context.Context, userID uint) error
+	ListActiveByUserID(ctx context.Context, userID uint) ([]*models.RefreshToken, error)
+	RevokeFamilyByUserID(ctx context.Context, userID uint, familyID string) error
+	RevokeAllByUserIDExcept(ctx context.Context, userID uint, exceptFamilyID string) error
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+}
+
+type refreshTokenRepo struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository はrefreshTokenRepoのコンストラクタです。
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{db: db}
+}
+
+func (r *refreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *refreshTokenRepo) GetByJTI(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("jti = ?", jti).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate は古いトークンを失効させ、新しいトークンを同じトランザクションで保存します。
+// 古いトークンが既に失効している場合（同時リクエストによる再利用など）はErrRefreshTokenAlreadyRotatedを返します。
+func (r *refreshTokenRepo) Rotate(ctx context.Context, oldJTI string, next *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RefreshToken{}).
+			Where("jti = ? AND revoked_at IS NULL", oldJTI).
+			Updates(map[string]interface{}{
+				"revoked_at":  time.Now(),
+				"replaced_by": next.JTI,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenAlreadyRotated
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeFamily は同じファミリーに属する未失効のトークンをすべて失効させます。
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByUserID はユーザーの未失効のトークンをすべて失効させます。
+func (r *refreshTokenRepo) RevokeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ListActiveByUserID はユーザーの有効なセッション（各ファミリーの未失効・未期限切れのトークン）を最終利用日時の新しい順に返します。
+func (r *refreshTokenRepo) ListActiveByUserID(ctx context.Context, userID uint) ([]*models.RefreshToken, error) {
+	var tokens []*models.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeFamilyByUserID は指定したユーザーのセッションを失効させます。
+// 該当する有効なセッションがない場合はErrSessionNotFoundを返します。
+func (r *refreshTokenRepo) RevokeFamilyByUserID(ctx context.Context, userID uint, familyID string) error {
+	res := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllByUserIDExcept は指定したセッション以外のユーザーのセッションをすべて失効させます。
+func (r *refreshTokenRepo) RevokeAllByUserIDExcept(ctx context.Context, userID uint, exceptFamilyID string) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, exceptFamilyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// IsFamilyActive はセッションに未失効・未期限切れのトークンが存在するかを判定します。
+func (r *refreshTokenRepo) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL AND expires_at > ?", familyID, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -3,20 +3,49 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"strings"
+	"time"
 
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// ErrUserNotFound は更新・削除の対象のユーザーが存在しない場合のエラーです。
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUsernameTaken はユーザー名の一意制約に違反した場合のエラーです。
+// 使用済みかどうかの確認と更新の間に同じユーザー名が登録された場合に返ります。
+var ErrUsernameTaken = errors.New("username already taken")
+
+// likeEscaper はLIKEのパターンで特別な意味を持つ文字をエスケープします（ESCAPE '\'と組み合わせて使用）。
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // UserRepository はユーザーに関するDB操作インターフェースです。
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
+	CreateWithOAuthAccount(ctx context.Context, user *models.User, account *models.OAuthAccount) error
 	GetByID(ctx context.Context, id uint) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	UpdatePassword(ctx context.Context, userID uint, newHash string) error
 	GetIDByUsername(ctx context.Context, username string) (uint, error)
+	List(ctx context.Context, query string, offset, limit int) ([]*models.User, int64, error)
+	UpdateStatus(ctx context.Context, userID uint, status, reason string, bannedAt *time.Time) error
+	SetPasswordResetRequired(ctx context.Context, userID uint, required bool) error
+	UpdateRole(ctx context.Context, userID uint, role string) error
+	Delete(ctx context.Context, userID uint) error
+	MarkEmailVerified(ctx context.Context, userID uint, email string) error
+	UpdateVerificationSentAt(ctx context.Context, userID uint, sentAt time.Time) error
+	SetTOTPSecret(ctx context.Context, userID uint, secret string) error
+	EnableTOTP(ctx context.Context, userID uint) error
+	DisableTOTP(ctx context.Context, userID uint) error
+	AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	RecordTOTPFailure(ctx context.Context, userID uint, maxAttempts int, lockedUntil time.Time) (bool, error)
+	ResetTOTPFailures(ctx context.Context, userID uint) error
+	UpdateProfile(ctx context.Context, user *models.User) error
+	SetPendingEmail(ctx context.Context, userID uint, email string) error
+	ChangeEmail(ctx context.Context, userID uint, newEmail string) error
 }
 
 // userRepo はUserRepositoryの実装です。
@@ -33,6 +62,18 @@ func (r *userRepo) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+// CreateWithOAuthAccount はユーザーと連携するOAuthAccountを1つのトランザクションで作成します。
+// account.UserIDは作成したユーザーのIDに設定します。どちらかの作成に失敗した場合はどちらも作成しません。
+func (r *userRepo) CreateWithOAuthAccount(ctx context.Context, user *models.User, account *models.OAuthAccount) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		account.UserID = user.ID
+		return tx.Create(account).Error
+	})
+}
+
 func (r *userRepo) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
 	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
@@ -45,28 +86,20 @@ func (r *userRepo) GetByID(ctx context.Context, id uint) (*models.User, error) {
 }
 
 // GetByEmail は email からユーザーを取得します
+// メールアドレスのないユーザー（空文字列）は複数いるため、空のemailでは検索せずnilを返します
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email == "" {
+		return nil, nil
+	}
 	var user models.User
 
-	// GORMのResultオブジェクトを受け取るパターンに修正
-	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
-
-	// エラーハンドリング
-	if result.Error != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		// レコードが見つからないエラーの場合、nilを返す
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			log.Printf("User with email '%s' not found.", email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		// その他のエラーの場合、エラーを返す
-		log.Printf("Database error fetching user by email '%s': %v", email, result.Error)
-		return nil, result.Error
+		return nil, err
 	}
-
-	// デバッグログ: ユーザーが見つかったことを確認
-	log.Printf("Found user with email '%s'. Rows affected: %d", email, result.RowsAffected)
-
-	// 成功した場合、ユーザーポインタとnilエラーを返す
 	return &user, nil
 }
 
@@ -87,7 +120,7 @@ func (r *userRepo) UpdatePassword(ctx context.Context, userID uint, newHash stri
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 	return nil
 }
@@ -106,3 +139,265 @@ func (r *userRepo) GetIDByUsername(ctx context.Context, username string) (uint,
 	// ユーザーIDを返す
 	return user.ID, nil
 }
+
+// List はユーザー名またはメールアドレスの部分一致でユーザーを検索し、総件数とともに返します。
+// queryが空の場合は全ユーザーを対象とします。
+func (r *userRepo) List(ctx context.Context, query string, offset, limit int) ([]*models.User, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.User{})
+	if query != "" {
+		// 検索語の%や_はワイルドカードではなく文字として扱う
+		like := "%" + likeEscaper.Replace(query) + "%"
+		db = db.Where(`username ILIKE ? ESCAPE '\' OR email ILIKE ? ESCAPE '\'`, like, like)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*models.User
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// UpdateStatus はユーザーのアカウント状態（BAN等）を更新します。
+func (r *userRepo) UpdateStatus(ctx context.Context, userID uint, status, reason string, bannedAt *time.Time) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"status":     status,
+		"ban_reason": reason,
+		"banned_at":  bannedAt,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetPasswordResetRequired はパスワード再設定の強制フラグを更新します。
+func (r *userRepo) SetPasswordResetRequired(ctx context.Context, userID uint, required bool) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("password_reset_required", required)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateRole はユーザーのロールを更新します。
+func (r *userRepo) UpdateRole(ctx context.Context, userID uint, role string) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete はユーザーと、そのユーザーに紐づくデータ（問題・提出・OAuthアカウント等）をまとめて削除します。
+func (r *userRepo) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		challengeIDs := tx.Model(&models.Challenge{}).Select("id").Where("user_id = ?", userID)
+
+		// 自分の提出と、自分の問題に対する他ユーザーの提出を削除
+		if err := tx.Where("user_id = ? OR challenge_id IN (?)", userID, challengeIDs).Delete(&models.Submission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("challenge_id IN (?)", challengeIDs).Delete(&models.ChallengeFile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("challenge_id IN (?)", challengeIDs).Delete(&models.DockerChallenge{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Challenge{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.OAuthAccount{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.APIToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.OAuthExchangeCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserAchievement{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.Delete(&models.User{}, userID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// MarkEmailVerified はメールアドレスを確認済みにします。
+// 確認リンク発行後にメールアドレスが変更されている場合は更新せずエラーを返します。
+func (r *userRepo) MarkEmailVerified(ctx context.Context, userID uint, email string) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateVerificationSentAt は確認メールの最終送信日時を更新します。
+func (r *userRepo) UpdateVerificationSentAt(ctx context.Context, userID uint, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("verification_sent_at", sentAt).Error
+}
+
+// SetTOTPSecret は登録中の二要素認証シークレットを保存します。
+func (r *userRepo) SetTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":         secret,
+		"totp_last_used_step": 0,
+	}).Error
+}
+
+// EnableTOTP は二要素認証を有効にします。
+func (r *userRepo) EnableTOTP(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_enabled":    true,
+		"totp_enabled_at": time.Now(),
+	}).Error
+}
+
+// DisableTOTP は二要素認証を無効にし、シークレットを削除します。
+func (r *userRepo) DisableTOTP(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":          "",
+		"totp_enabled":         false,
+		"totp_enabled_at":      nil,
+		"totp_last_used_step":  0,
+		"totp_failed_attempts": 0,
+		"totp_locked_until":    nil,
+	}).Error
+}
+
+// AdvanceTOTPStep は最後に使用したTOTPのタイムステップを更新します。
+// 既に同じか新しいステップが使用済みの場合はfalseを返します（コードの再利用防止）。
+func (r *userRepo) AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", userID, step).
+		Update("totp_last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// RecordTOTPFailure は二要素認証のコードを誤った回数を1増やします。
+// 回数がmaxAttemptsに達した場合は回数を0に戻してlockedUntilまでロックし、trueを返します。
+func (r *userRepo) RecordTOTPFailure(ctx context.Context, userID uint, maxAttempts int, lockedUntil time.Time) (bool, error) {
+	var locked bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("totp_failed_attempts", gorm.Expr("totp_failed_attempts + 1")).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND totp_failed_attempts >= ?", userID, maxAttempts).
+			Updates(map[string]interface{}{
+				"totp_failed_attempts": 0,
+				"totp_locked_until":    lockedUntil,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		locked = res.RowsAffected > 0
+		return nil
+	})
+	return locked, err
+}
+
+// ResetTOTPFailures は二要素認証のコードを誤った回数とロックを解除します。
+func (r *userRepo) ResetTOTPFailures(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_failed_attempts": 0,
+		"totp_locked_until":    nil,
+	}).Error
+}
+
+// UpdateProfile はユーザー名・表示名・自己紹介・アイコン画像のURL・解いた問題の公開設定を更新します。
+func (r *userRepo) UpdateProfile(ctx context.Context, user *models.User) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"username":            user.Username,
+		"display_name":        user.DisplayName,
+		"bio":                 user.Bio,
+		"avatar_url":          user.AvatarURL,
+		"username_changed_at": user.UsernameChangedAt,
+		"hide_solves":         user.HideSolves,
+	})
+	if isUniqueViolation(res.Error) {
+		// プロフィールで一意制約があるのはユーザー名だけ
+		return ErrUsernameTaken
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetPendingEmail は確認待ちの新しいメールアドレスを保存します。
+func (r *userRepo) SetPendingEmail(ctx context.Context, userID uint, email string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("pending_email", email).Error
+}
+
+// ChangeEmail は確認待ちのメールアドレスを確認済みのメールアドレスとして確定します。
+// 確認リンク発行後に確認待ちのメールアドレスが変更されている場合は更新せずエラーを返します。
+func (r *userRepo) ChangeEmail(ctx context.Context, userID uint, newEmail string) error {
+	res := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND pending_email = ?", userID, newEmail).
+		Updates(map[string]interface{}{
+			"email":             newEmail,
+			"pending_email":     "",
+			"email_verified":    true,
+			"email_verified_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation はPostgreSQLの一意制約違反（SQLSTATE 23505）かどうかを判定します。
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
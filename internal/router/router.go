@@ -1,10 +1,22 @@
 package router
 
 import (
+	"context"
+	"log/slog"
+	"time"
+
 	"github.com/CTF-Forge/CTF-Forge-backend/config"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/achievement"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/health"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/metrics"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/service"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/mailer"
 	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -15,45 +27,131 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
-	r := gin.Default()
+// sessionRevocationCacheTTL セッション失効状態をキャッシュする時間
+// セッションを失効させてから、そのアクセストークンが拒否されるまで最大でこの時間かかる
+const sessionRevocationCacheTTL = 30 * time.Second
+
+func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
+	r := gin.New()
+	// トレースのスパンを開始し、リクエストIDとトレースIDつきのロガーをコンテキストに格納してから、アクセスログを行う
+	r.Use(tracing.Middleware(cfg.Tracing.ServiceName), logging.Middleware(slog.Default()))
 
 	// CORSミドルウェアの設定
 	dbconfig := cors.DefaultConfig()
 	dbconfig.AllowOrigins = []string{"*"} // 本番環境では特定のオリジンに制限してください
-	dbconfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	dbconfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
-	dbconfig.ExposeHeaders = []string{"Content-Length"}
+	dbconfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	dbconfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", logging.RequestIDHeader, "traceparent", "tracestate"}
+	dbconfig.ExposeHeaders = []string{"Content-Length", logging.RequestIDHeader}
 	dbconfig.AllowCredentials = true
 
 	r.Use(cors.New(dbconfig))
 
+	// レスポンスの言語をAccept-Languageから選択する（CORSがVaryヘッダーを設定した後に追加する）
+	r.Use(i18n.Middleware())
+
+	// メトリクス
+	if cfg.Metrics.Enabled {
+		r.Use(metrics.Middleware())
+		if cfg.Metrics.Addr == "" {
+			r.GET("/metrics", gin.WrapH(metrics.Handler(cfg.Metrics.BearerToken)))
+		}
+	}
+
+	// エラーをapplication/problem+jsonのレスポンスに変換する。panicもエラーとして変換するため、Recoveryはこの内側に置く
+	// メトリクスとアクセスログに変換後のステータスが記録されるように、それらのミドルウェアより内側に置く
+	r.Use(handler.ErrorHandler(), logging.Recovery())
+	r.NoRoute(handler.NoRoute)
+
 	// セッションストア
-	gothic.Store = sessions.NewCookieStore([]byte(config.GetSessionSecret()))
+	gothic.Store = sessions.NewCookieStore([]byte(cfg.Session.Secret))
 
 	// リポジトリの初期化
 	userRepo := repository.NewUserRepository(db)
 	oauthRepo := repository.NewOAuthAccountRepository(db)
 	challengeRepo := repository.NewChallengeRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetTokenRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	oauthExchangeRepo := repository.NewOAuthExchangeCodeRepository(db)
+	achievementRepo := repository.NewAchievementRepository(db)
+	pendingSignupRepo := repository.NewPendingOAuthSignupRepository(db)
 
 	// JWTマネージャーの初期化
 	jwtManager := token.NewJWTManager(
-		config.GetJWTAccessSecret(),
-		config.GetJWTRefreshSecret(),
-		config.GetJWTIssuer(),
-		config.GetJWTAccessExpireDuration(),
-		config.GetJWTRefreshExpireDuration(),
+		cfg.JWT.AccessSecret,
+		cfg.JWT.RefreshSecret,
+		cfg.JWT.Issuer,
+		cfg.JWT.AccessTTL,
+		cfg.JWT.RefreshTTL,
 	)
 
+	// メール送信の初期化
+	mailSender := newMailer(cfg.Mail)
+
 	// サービスの初期化
-	authService := service.NewAuthService(userRepo, jwtManager)
-	oauthService := service.NewOAuthService(oauthRepo, userRepo, jwtManager)
-	challengeService := service.NewChallengeService(challengeRepo, userRepo)
+	tokenService := service.NewTokenService(refreshTokenRepo, userRepo, jwtManager)
+	emailVerifyService := service.NewEmailVerificationService(
+		userRepo,
+		mailSender,
+		cfg.EmailVerification.Secret,
+		cfg.FrontendURL+"/verify-email",
+		cfg.FrontendURL+"/confirm-email-change",
+		cfg.EmailVerification.TTL,
+		cfg.EmailVerification.ResendCooldown,
+	)
+	twoFactorService := service.NewTwoFactorService(userRepo, recoveryCodeRepo, cfg.TOTP.Issuer)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo)
+	authService := service.NewAuthService(userRepo, jwtManager, tokenService, emailVerifyService, twoFactorService)
+	oauthService := service.NewOAuthService(
+		oauthRepo,
+		userRepo,
+		oauthExchangeRepo,
+		pendingSignupRepo,
+		tokenService,
+		jwtManager,
+		cfg.OAuth.RedirectAllowlist,
+		cfg.OAuth.ChooseUsername,
+	)
+	achievementService := service.NewAchievementService(achievementRepo, challengeRepo, achievement.NewDefaultRegistry())
+	profileService := service.NewProfileService(
+		userRepo,
+		challengeRepo,
+		authService,
+		tokenService,
+		emailVerifyService,
+		achievementService,
+		cfg.Username.ChangeCooldown,
+	)
+	challengeService := service.NewChallengeService(challengeRepo, userRepo, achievementService)
+	adminService := service.NewAdminService(userRepo, challengeRepo, tokenService)
+	passwordResetService := service.NewPasswordResetService(
+		userRepo,
+		passwordResetRepo,
+		authService,
+		tokenService,
+		mailSender,
+		cfg.FrontendURL+"/reset-password",
+		cfg.PasswordReset.TTL,
+		cfg.PasswordReset.ResendCooldown,
+	)
 
 	// ハンドラーの初期化
 	authHandler := handler.NewAuthHandler(authService)
 	oauthHandler := handler.NewOAuthHandler(oauthService, jwtManager)
 	challengeHandler := handler.NewChallengeHandler(challengeService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	sessionHandler := handler.NewSessionHandler(tokenService)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+	emailVerifyHandler := handler.NewEmailVerificationHandler(emailVerifyService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	profileHandler := handler.NewProfileHandler(profileService)
+	achievementHandler := handler.NewAchievementHandler(achievementService)
+	healthHandler := handler.NewHealthHandler(newHealthRegistry(db, mailSender, cfg.Health))
+
+	// 失効済みセッションのアクセストークンを拒否するためのキャッシュ
+	sessionCache := token.NewRevocationCache(tokenService, sessionRevocationCacheTTL)
 
 	// Swagger UI
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -64,44 +162,128 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 		// Email認証
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/login/mfa", authHandler.LoginMFA)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
 		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/password/forgot", passwordResetHandler.ForgotPassword)
+		authGroup.POST("/password/reset", passwordResetHandler.ResetPassword)
+		authGroup.POST("/email/verify", emailVerifyHandler.VerifyEmail)
+		authGroup.POST("/email/change/confirm", emailVerifyHandler.ConfirmEmailChange)
 
 		// OAuth認証
+		authGroup.GET("/providers", oauthHandler.ListProviders)
 		authGroup.GET("/:provider", oauthHandler.BeginAuthHandler)
 		authGroup.GET("/:provider/callback", oauthHandler.CallbackAuthHandler)
+		authGroup.POST("/oauth/exchange", oauthHandler.ExchangeCode)
+		authGroup.POST("/oauth/signup", oauthHandler.CompleteSignup)
 		authGroup.POST("/oauth/refresh", oauthHandler.RefreshTokenHandler)
 		authGroup.POST("/oauth/logout", oauthHandler.LogoutHandler)
 	}
 
 	// 保護されたAPIグループ（認証が必要）
+	// パーソナルアクセストークンでもアクセスでき、その場合はRequireScopeで指定したスコープが必要
 	protectedGroup := r.Group("/api")
-	protectedGroup.Use(token.AuthMiddleware(jwtManager))
+	protectedGroup.Use(token.AuthMiddleware(jwtManager, sessionCache, apiTokenService))
 	{
 		// ユーザー関連
-		protectedGroup.GET("/me", authHandler.Me)
-		protectedGroup.POST("/challenges", challengeHandler.CreateChallenge)
-		protectedGroup.GET("/challenges", challengeHandler.CollectChallengesByUsername)
-		protectedGroup.GET("/challenges/:challengeId", challengeHandler.GetChallenge)
-		protectedGroup.PUT("/challenges/:challengeId", challengeHandler.UpdateChallenge)
-		protectedGroup.DELETE("/challenges/:challengeId", challengeHandler.DeleteChallenge)
-		protectedGroup.POST("/challenges/:challengeId/submit", challengeHandler.SubmitFlag)
+		protectedGroup.GET("/me", token.RequireScope(token.ScopeProfileRead), authHandler.Me)
+		protectedGroup.POST("/challenges", token.RequireScope(token.ScopeChallengesWrite), challengeHandler.CreateChallenge)
+		protectedGroup.GET("/challenges", token.RequireScope(token.ScopeChallengesRead), challengeHandler.CollectMyChallenges)
+		protectedGroup.GET("/challenges/:challengeId", token.RequireScope(token.ScopeChallengesRead), challengeHandler.GetChallenge)
+		protectedGroup.PUT("/challenges/:challengeId", token.RequireScope(token.ScopeChallengesWrite), challengeHandler.UpdateChallenge)
+		protectedGroup.DELETE("/challenges/:challengeId", token.RequireScope(token.ScopeChallengesWrite), challengeHandler.DeleteChallenge)
+		protectedGroup.POST("/challenges/:challengeId/submit", token.RequireScope(token.ScopeSubmit), challengeHandler.SubmitFlag)
 		// ここに他の保護されたエンドポイントを追加
 		// 例: 問題作成、提出履歴など
 	}
 
+	// アカウント管理APIグループ（ログインしたユーザー本人のみ。パーソナルアクセストークンは使用不可）
+	accountGroup := protectedGroup.Group("/me", token.DenyAPIToken())
+	{
+		accountGroup.PATCH("", profileHandler.UpdateProfile)
+		accountGroup.POST("/password", profileHandler.ChangePassword)
+		accountGroup.POST("/email", profileHandler.ChangeEmail)
+		accountGroup.POST("/email/verification", emailVerifyHandler.ResendVerification)
+		accountGroup.GET("/sessions", sessionHandler.ListSessions)
+		accountGroup.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+		accountGroup.DELETE("/sessions/:sessionId", sessionHandler.RevokeSession)
+		accountGroup.POST("/2fa/totp/enroll", twoFactorHandler.EnrollTOTP)
+		accountGroup.POST("/2fa/totp/confirm", twoFactorHandler.ConfirmTOTP)
+		accountGroup.POST("/2fa/totp/disable", twoFactorHandler.DisableTOTP)
+		accountGroup.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+		accountGroup.GET("/tokens", apiTokenHandler.ListAPITokens)
+		accountGroup.POST("/tokens", apiTokenHandler.CreateAPIToken)
+		accountGroup.DELETE("/tokens/:tokenId", apiTokenHandler.RevokeAPIToken)
+		accountGroup.GET("/identities", oauthHandler.ListIdentities)
+		accountGroup.POST("/identities/:provider", oauthHandler.StartLink)
+		accountGroup.DELETE("/identities/:identityId", oauthHandler.UnlinkIdentity)
+	}
+
+	// 管理者APIグループ（adminロールが必要。パーソナルアクセストークンは使用不可）
+	adminGroup := r.Group("/api/admin")
+	adminGroup.Use(token.AuthMiddleware(jwtManager, sessionCache, apiTokenService), token.DenyAPIToken(), token.RequireRole(models.RoleAdmin))
+	{
+		adminGroup.GET("/users", adminHandler.ListUsers)
+		adminGroup.GET("/users/:userId", adminHandler.GetUser)
+		adminGroup.DELETE("/users/:userId", adminHandler.DeleteUser)
+		adminGroup.GET("/users/:userId/challenges", adminHandler.GetUserChallenges)
+		adminGroup.GET("/users/:userId/submissions", adminHandler.GetUserSubmissions)
+		adminGroup.POST("/users/:userId/ban", adminHandler.BanUser)
+		adminGroup.POST("/users/:userId/unban", adminHandler.UnbanUser)
+		adminGroup.POST("/users/:userId/password-reset", adminHandler.ForcePasswordReset)
+		adminGroup.PUT("/users/:userId/role", adminHandler.SetUserRole)
+	}
+
 	// 公開APIグループ（認証オプショナル）
 	publicGroup := r.Group("/api/public")
-	publicGroup.Use(token.OptionalAuthMiddleware(jwtManager))
+	publicGroup.Use(token.OptionalAuthMiddleware(jwtManager, sessionCache, apiTokenService))
 	{
 		// 問題一覧など、認証されていないユーザーもアクセス可能なエンドポイント
 		publicGroup.GET("/challenges", challengeHandler.GetAllPublicChallenges)
+		publicGroup.GET("/achievements", achievementHandler.ListAchievements)
+		publicGroup.GET("/users/:username", profileHandler.GetPublicProfile)
+		publicGroup.GET("/users/:username/challenges", challengeHandler.CollectChallengesByUsername)
 	}
 
-	// ヘルスチェック
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// ヘルスチェック（/healthはKubernetesなどの設定変更前の互換のため残す）
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/health", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
 
 	return r
-}
\ No newline at end of file
+}
+
+// newHealthRegistry はレディネスチェックに使用するコンポーネントを登録します。
+// データベースは必須、メール送信は失敗してもログインなどは利用できるため必須ではありません。
+// 問題ファイルの保存先と問題のコンテナの実行環境は、まだ実装がないためチェックを登録していません。
+// それらを追加するときはここでチェックを登録してください。
+func newHealthRegistry(db *gorm.DB, mailSender mailer.Mailer, cfg config.HealthConfig) *health.Registry {
+	registry := health.NewRegistry(cfg.CheckTimeout)
+	registry.Register("database", true, func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	if checker, ok := mailSender.(mailer.Checker); ok {
+		registry.Register("mailer", false, checker.Check)
+	}
+	return registry
+}
+
+// newMailer は設定されたメールドライバーに応じたMailerを作成します。
+func newMailer(cfg config.MailConfig) mailer.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			cfg.SMTP.Host,
+			cfg.SMTP.Port,
+			cfg.SMTP.Username,
+			cfg.SMTP.Password,
+			cfg.From,
+		)
+	default:
+		return mailer.NewLogMailer(cfg.From, cfg.LogDir)
+	}
+}
@@ -0,0 +1,92 @@
+// Package server はHTTPサーバーの起動と停止を管理します。
+//
+// Runに渡したコンテキストが終了すると（SIGINT・SIGTERMの受信など）、新しい接続の受け付けを止めて
+// 処理中のリクエストの完了を待ち、その後OnShutdownで登録した停止処理を登録した順に実行します。
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+)
+
+// Server は停止処理つきのHTTPサーバーです。
+type Server struct {
+	cfg   config.ServerConfig
+	http  *http.Server
+	hooks []hook
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New はServerのコンストラクタです。
+func New(cfg config.ServerConfig, handler http.Handler) *Server {
+	return &Server{
+		cfg: cfg,
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+	}
+}
+
+// OnShutdown はHTTPサーバーの停止後に実行する処理を登録します。登録した順に実行するため、
+// 他に依存される資源（データベースの接続プールなど）は最後に登録してください。
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, hook{name: name, fn: fn})
+}
+
+// Run はサーバーを起動し、ctxが終了するまで待ち受けます。
+// ctxの終了後はserver.shutdown_timeoutの間だけ処理中のリクエストを待ってから停止処理を実行します。
+// 待ち受けに失敗した場合も登録した停止処理を実行します。
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if s.cfg.TLSEnabled() {
+			slog.Info("starting server", "addr", s.cfg.Addr, "tls", true)
+			err = s.http.ListenAndServeTLS(s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+		} else {
+			slog.Info("starting server", "addr", s.cfg.Addr, "tls", false)
+			err = s.http.ListenAndServe()
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		serveErr <- err
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to run server: %w", err))
+		}
+	case <-ctx.Done():
+		slog.Info("shutting down server", "addr", s.cfg.Addr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain connections: %w", err))
+	}
+	for _, h := range s.hooks {
+		if err := h.fn(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", h.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
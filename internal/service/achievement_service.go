@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/achievement"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+)
+
+// AchievementService は実績の判定と解除済みの実績の取得を行います。
+type AchievementService struct {
+	achievementRepo repository.AchievementRepository
+	challengeRepo   repository.ChallengeRepository
+	registry        *achievement.Registry
+}
+
+func NewAchievementService(
+	achievementRepo repository.AchievementRepository,
+	challengeRepo repository.ChallengeRepository,
+	registry *achievement.Registry,
+) *AchievementService {
+	return &AchievementService{
+		achievementRepo: achievementRepo,
+		challengeRepo:   challengeRepo,
+		registry:        registry,
+	}
+}
+
+// Evaluate はイベントの後にユーザーの実績を判定し、新しく解除した実績をコンテキストの言語で返します。
+func (s *AchievementService) Evaluate(ctx context.Context, userID uint, event achievement.Event, challengeID uint) ([]*dtos.AchievementDTO, error) {
+	ctx, span := tracing.Start(ctx, "AchievementService.Evaluate")
+	defer span.End()
+
+	existing, err := s.achievementRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[string]bool, len(existing))
+	for _, a := range existing {
+		unlocked[a.AchievementID] = true
+	}
+
+	rules, err := s.registry.Evaluate(ctx, &achievement.Input{
+		UserID:      userID,
+		Event:       event,
+		ChallengeID: challengeID,
+		Stats:       &userStats{challengeRepo: s.challengeRepo, userID: userID},
+	}, unlocked)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.FromContext(ctx)
+	var res []*dtos.AchievementDTO
+	now := time.Now()
+	for _, rule := range rules {
+		ok, err := s.achievementRepo.Unlock(ctx, userID, rule.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, toAchievementDTO(locale, rule, &now))
+		}
+	}
+	return res, nil
+}
+
+// ListUnlocked はユーザーが解除した実績を解除日時の古い順にコンテキストの言語で返します。定義が削除された実績は含みません。
+// hideSolvesがtrueの場合は、解いた問題が分かってしまうフラグの正解で判定する実績（First Bloodなど）を含みません。
+func (s *AchievementService) ListUnlocked(ctx context.Context, userID uint, hideSolves bool) ([]*dtos.AchievementDTO, error) {
+	ctx, span := tracing.Start(ctx, "AchievementService.ListUnlocked")
+	defer span.End()
+
+	achievements, err := s.achievementRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.FromContext(ctx)
+	res := make([]*dtos.AchievementDTO, 0, len(achievements))
+	for _, a := range achievements {
+		rule, ok := s.registry.Get(a.AchievementID)
+		if !ok || (hideSolves && rule.Handles(achievement.EventSolve)) {
+			continue
+		}
+		unlockedAt := a.UnlockedAt
+		res = append(res, toAchievementDTO(locale, rule, &unlockedAt))
+	}
+	return res, nil
+}
+
+// Definitions は登録されているすべての実績をコンテキストの言語で返します。
+func (s *AchievementService) Definitions(ctx context.Context) []*dtos.AchievementDTO {
+	locale := i18n.FromContext(ctx)
+	rules := s.registry.Rules()
+	res := make([]*dtos.AchievementDTO, len(rules))
+	for i, rule := range rules {
+		res[i] = toAchievementDTO(locale, rule, nil)
+	}
+	return res
+}
+
+// toAchievementDTO は実績の表示名と解除条件の説明をlocaleの言語のカタログから作成します。
+func toAchievementDTO(locale string, rule *achievement.Rule, unlockedAt *time.Time) *dtos.AchievementDTO {
+	return &dtos.AchievementDTO{
+		ID:          rule.ID,
+		Name:        i18n.T(locale, rule.NameKey(), nil),
+		Description: i18n.T(locale, rule.DescriptionKey, rule.Params),
+		UnlockedAt:  unlockedAt,
+	}
+}
+
+// userStats はachievement.Statsの実装です。1回の判定の間、解いた問題の一覧をキャッシュします。
+type userStats struct {
+	challengeRepo repository.ChallengeRepository
+	userID        uint
+	solves        []*repository.SolveRecord
+	solvesLoaded  bool
+}
+
+func (st *userStats) loadSolves(ctx context.Context) ([]*repository.SolveRecord, error) {
+	if !st.solvesLoaded {
+		solves, err := st.challengeRepo.CollectSolvesByUserID(ctx, st.userID)
+		if err != nil {
+			return nil, err
+		}
+		st.solves = solves
+		st.solvesLoaded = true
+	}
+	return st.solves, nil
+}
+
+func (st *userStats) SolveCount(ctx context.Context) (int, error) {
+	solves, err := st.loadSolves(ctx)
+	return len(solves), err
+}
+
+func (st *userStats) CategorySolveCount(ctx context.Context, category string) (int, error) {
+	solves, err := st.loadSolves(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, solve := range solves {
+		if strings.EqualFold(solve.Category, category) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (st *userStats) IsFirstBlood(ctx context.Context, challengeID uint) (bool, error) {
+	firstSolverID, err := st.challengeRepo.GetFirstSolverID(ctx, challengeID)
+	return err == nil && firstSolverID == st.userID, err
+}
+
+func (st *userStats) PublishedCount(ctx context.Context) (int, error) {
+	challenges, err := st.challengeRepo.CollectPublicByUserID(ctx, st.userID)
+	return len(challenges), err
+}
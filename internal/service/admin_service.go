@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+var (
+	ErrUserNotFound      = apperror.NotFound("user_not_found", "user not found")
+	ErrCannotModifySelf  = apperror.Forbidden("cannot_modify_self", "cannot perform this action on your own account")
+	ErrCannotModifyAdmin = apperror.Forbidden("cannot_modify_admin", "cannot perform this action on an admin account")
+	ErrInvalidRole       = apperror.Validation("invalid_role", "invalid role").WithParam("allowed", models.RoleUser+", "+models.RoleAdmin)
+)
+
+// AdminService は管理者によるユーザー管理のビジネスロジックを提供します。
+type AdminService struct {
+	userRepo      repository.UserRepository
+	challengeRepo repository.ChallengeRepository
+	tokenService  *TokenService
+}
+
+func NewAdminService(userRepo repository.UserRepository, challengeRepo repository.ChallengeRepository, tokenService *TokenService) *AdminService {
+	return &AdminService{
+		userRepo:      userRepo,
+		challengeRepo: challengeRepo,
+		tokenService:  tokenService,
+	}
+}
+
+// ListUsers はユーザー名・メールアドレスで検索したユーザー一覧をページ単位で返します。
+func (s *AdminService) ListUsers(ctx context.Context, query string, page, limit int) (*dtos.UserListResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.ListUsers")
+	defer span.End()
+
+	users, total, err := s.userRepo.List(ctx, query, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dtos.UserListResponse{
+		Users: make([]*dtos.AdminUserDTO, len(users)),
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+	for i, user := range users {
+		res.Users[i] = toAdminUserDTO(user)
+	}
+	return res, nil
+}
+
+// GetUser はユーザー情報を取得します。
+func (s *AdminService) GetUser(ctx context.Context, userID uint) (*dtos.AdminUserDTO, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetUser")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserDTO(user), nil
+}
+
+// GetUserChallenges はユーザーが作成した問題を非公開のものも含めてすべて返します。
+func (s *AdminService) GetUserChallenges(ctx context.Context, userID uint) ([]*models.Challenge, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetUserChallenges")
+	defer span.End()
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.challengeRepo.CollectByUserID(ctx, userID)
+}
+
+// GetUserSubmissions はユーザーの提出履歴を返します。
+func (s *AdminService) GetUserSubmissions(ctx context.Context, userID uint) ([]*dtos.SubmissionDTO, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetUserSubmissions")
+	defer span.End()
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.challengeRepo.CollectSubmissionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dtos.SubmissionDTO, len(submissions))
+	for i, sub := range submissions {
+		res[i] = &dtos.SubmissionDTO{
+			ID:             sub.ID,
+			ChallengeID:    sub.ChallengeID,
+			ChallengeTitle: sub.Challenge.Title,
+			IsCorrect:      sub.IsCorrect,
+			SubmittedAt:    sub.SubmittedAt,
+		}
+	}
+	return res, nil
+}
+
+// BanUser はユーザーをBANします。BANされたユーザーはログイン・トークン更新・フラグ提出ができなくなります。
+func (s *AdminService) BanUser(ctx context.Context, adminID, userID uint, reason string) error {
+	ctx, span := tracing.Start(ctx, "AdminService.BanUser")
+	defer span.End()
+
+	if _, err := s.getModifiableUser(ctx, adminID, userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.userRepo.UpdateStatus(ctx, userID, models.UserStatusBanned, reason, &now); err != nil {
+		return userNotFound(err)
+	}
+	return s.tokenService.RevokeAllForUser(ctx, userID)
+}
+
+// UnbanUser はユーザーのBANを解除します。
+func (s *AdminService) UnbanUser(ctx context.Context, adminID, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AdminService.UnbanUser")
+	defer span.End()
+
+	if _, err := s.getModifiableUser(ctx, adminID, userID); err != nil {
+		return err
+	}
+	return userNotFound(s.userRepo.UpdateStatus(ctx, userID, models.UserStatusActive, "", nil))
+}
+
+// ForcePasswordReset は次回ログイン前にパスワードの再設定を要求します。
+func (s *AdminService) ForcePasswordReset(ctx context.Context, adminID, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AdminService.ForcePasswordReset")
+	defer span.End()
+
+	if _, err := s.getModifiableUser(ctx, adminID, userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.SetPasswordResetRequired(ctx, userID, true); err != nil {
+		return userNotFound(err)
+	}
+	return s.tokenService.RevokeAllForUser(ctx, userID)
+}
+
+// SetUserRole はユーザーのロールを変更します。他の管理者の降格もできますが、自分自身のロールは変更できません。
+func (s *AdminService) SetUserRole(ctx context.Context, adminID, userID uint, role string) error {
+	ctx, span := tracing.Start(ctx, "AdminService.SetUserRole")
+	defer span.End()
+
+	if adminID == userID {
+		return ErrCannotModifySelf
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.assignRole(ctx, user, role)
+}
+
+// AssignRoleByUsername はユーザー名で指定したユーザーのロールを変更します。
+// 最初の管理者の設定など、管理者APIを使用できない場合にコマンドラインから使用します。
+func (s *AdminService) AssignRoleByUsername(ctx context.Context, username, role string) error {
+	ctx, span := tracing.Start(ctx, "AdminService.AssignRoleByUsername")
+	defer span.End()
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return s.assignRole(ctx, user, role)
+}
+
+// assignRole はロールを更新し、ユーザーのセッションをすべて失効させます。
+// アクセストークンには発行時点のロールが含まれるため、失効させないと降格後も有効期限まで以前のロールで操作できてしまう
+func (s *AdminService) assignRole(ctx context.Context, user *models.User, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return ErrInvalidRole.WithParam("role", role)
+	}
+	if user.Role == role {
+		return nil
+	}
+	if err := s.userRepo.UpdateRole(ctx, user.ID, role); err != nil {
+		return userNotFound(err)
+	}
+	return s.tokenService.RevokeAllForUser(ctx, user.ID)
+}
+
+// DeleteUser はユーザーと関連データを削除します。
+func (s *AdminService) DeleteUser(ctx context.Context, adminID, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AdminService.DeleteUser")
+	defer span.End()
+
+	if _, err := s.getModifiableUser(ctx, adminID, userID); err != nil {
+		return err
+	}
+	return userNotFound(s.userRepo.Delete(ctx, userID))
+}
+
+func (s *AdminService) getUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// getModifiableUser は自分自身や他の管理者を対象とした操作を拒否します。
+func (s *AdminService) getModifiableUser(ctx context.Context, adminID, userID uint) (*models.User, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, ErrCannotModifyAdmin
+	}
+	return user, nil
+}
+
+// userNotFound は取得後に削除されたユーザーの更新で返るリポジトリのエラーをErrUserNotFound（404）に変換します。
+func userNotFound(err error) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func toAdminUserDTO(user *models.User) *dtos.AdminUserDTO {
+	return &dtos.AdminUserDTO{
+		ID:                    user.ID,
+		Username:              user.Username,
+		Email:                 user.Email,
+		Role:                  user.Role,
+		Status:                user.Status,
+		BanReason:             user.BanReason,
+		BannedAt:              user.BannedAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		EmailVerified:         user.EmailVerified,
+		CreatedAt:             user.CreatedAt,
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+)
+
+const (
+	apiTokenDisplayPrefixLen = len(token.APITokenPrefix) + 6
+	// apiTokenTouchInterval は最終利用日時を更新する最小間隔です。リクエストごとの書き込みを避けます。
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidScope     = apperror.Validation("invalid_scope", "invalid scope").WithParam("allowed", strings.Join(token.AllScopes, ", "))
+	ErrScopeRequired    = apperror.Validation("scope_required", "at least one scope is required").WithParam("allowed", strings.Join(token.AllScopes, ", "))
+	ErrAPITokenNotFound = apperror.NotFound("api_token_not_found", "api token not found")
+)
+
+// APITokenService はパーソナルアクセストークンの発行・失効・検証を行います。
+type APITokenService struct {
+	apiTokenRepo repository.APITokenRepository
+}
+
+func NewAPITokenService(apiTokenRepo repository.APITokenRepository) *APITokenService {
+	return &APITokenService{apiTokenRepo: apiTokenRepo}
+}
+
+// Create は新しいトークンを発行します。トークン本体は戻り値でのみ参照でき、DBにはハッシュを保存します。
+// expiresInDaysが0の場合は無期限です。
+func (s *APITokenService) Create(ctx context.Context, userID uint, req *dtos.CreateAPITokenRequest) (*dtos.APITokenCreatedResponse, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.Create")
+	defer span.End()
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	raw := token.APITokenPrefix + secret
+
+	t := &models.APIToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		TokenPrefix: raw[:apiTokenDisplayPrefixLen],
+		TokenHash:   hashToken(raw),
+		Scopes:      strings.Join(scopes, " "),
+		CreatedAt:   time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := t.CreatedAt.AddDate(0, 0, req.ExpiresInDays)
+		t.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiTokenRepo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return &dtos.APITokenCreatedResponse{
+		APITokenDTO: *toAPITokenDTO(t),
+		Token:       raw,
+	}, nil
+}
+
+// List はユーザーの有効なトークンを返します。
+func (s *APITokenService) List(ctx context.Context, userID uint) ([]*dtos.APITokenDTO, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.List")
+	defer span.End()
+
+	tokens, err := s.apiTokenRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dtos.APITokenDTO, len(tokens))
+	for i, t := range tokens {
+		res[i] = toAPITokenDTO(t)
+	}
+	return res, nil
+}
+
+// Revoke はユーザー自身のトークンを失効させます。
+func (s *APITokenService) Revoke(ctx context.Context, userID, tokenID uint) error {
+	ctx, span := tracing.Start(ctx, "APITokenService.Revoke")
+	defer span.End()
+
+	if err := s.apiTokenRepo.Revoke(ctx, userID, tokenID); err != nil {
+		if errors.Is(err, repository.ErrAPITokenNotFound) {
+			return ErrAPITokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AuthenticateAPIToken はtoken.APITokenAuthenticatorの実装です。
+// 失効・期限切れのトークンや、BAN中・パスワード再設定が必要なユーザーのトークンは拒否します。
+func (s *APITokenService) AuthenticateAPIToken(ctx context.Context, rawToken string) (*token.APITokenPrincipal, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.AuthenticateAPIToken")
+	defer span.End()
+
+	t, err := s.apiTokenRepo.GetByTokenHash(ctx, hashToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if t == nil || !t.IsActive(now) {
+		return nil, token.ErrInvalidAPIToken
+	}
+	if t.User.IsBanned() || t.User.PasswordResetRequired {
+		return nil, token.ErrInvalidAPIToken
+	}
+
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.apiTokenRepo.TouchLastUsed(ctx, t.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &token.APITokenPrincipal{
+		TokenID:  t.ID,
+		UserID:   t.UserID,
+		Username: t.User.Username,
+		Role:     t.User.Role,
+		Scopes:   t.ScopeList(),
+	}, nil
+}
+
+// normalizeScopes はスコープを検証し、重複を除いて並べ替えます。
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	res := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !token.IsValidScope(scope) {
+			return nil, ErrInvalidScope.WithParam("scope", scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		res = append(res, scope)
+	}
+	if len(res) == 0 {
+		return nil, ErrScopeRequired
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func toAPITokenDTO(t *models.APIToken) *dtos.APITokenDTO {
+	return &dtos.APITokenDTO{
+		ID:          t.ID,
+		Name:        t.Name,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      t.ScopeList(),
+		ExpiresAt:   t.ExpiresAt,
+		LastUsedAt:  t.LastUsedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+}
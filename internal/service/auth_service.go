@@ -2,25 +2,48 @@ package service
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/metrics"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
 	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
 )
 
+var (
+	ErrUserBanned            = apperror.Forbidden("user_banned", "user is banned")
+	ErrPasswordResetRequired = apperror.Forbidden("password_reset_required", "password reset required")
+	// ErrInvalidCredentials はメールアドレスまたはパスワードが一致しない場合のエラーです。どちらが誤っているかは返しません。
+	ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "invalid email or password")
+)
+
 // AuthService は認証に関わるビジネスロジックを提供します。
 type AuthService struct {
-	userRepo   repository.UserRepository
-	jwtManager *token.JWTManager
+	userRepo           repository.UserRepository
+	jwtManager         *token.JWTManager
+	tokenService       *TokenService
+	emailVerifyService *EmailVerificationService
+	twoFactorService   *TwoFactorService
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtManager *token.JWTManager) *AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	jwtManager *token.JWTManager,
+	tokenService *TokenService,
+	emailVerifyService *EmailVerificationService,
+	twoFactorService *TwoFactorService,
+) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:           userRepo,
+		jwtManager:         jwtManager,
+		tokenService:       tokenService,
+		emailVerifyService: emailVerifyService,
+		twoFactorService:   twoFactorService,
 	}
 }
 
@@ -35,31 +58,139 @@ func (s *AuthService) VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// mfaTokenDuration は二要素認証待ちトークンの有効期間です。
+const mfaTokenDuration = 5 * time.Minute
+
+// LoginResult はログインの結果です。
+// 二要素認証が有効なユーザーの場合はTokenPairの代わりにMFATokenが設定され、CompleteMFALoginでコードを検証する必要があります。
+type LoginResult struct {
+	User         *models.User
+	TokenPair    *token.TokenPair
+	MFARequired  bool
+	MFAToken     string
+	MFAExpiresIn int64 // 秒単位
+}
+
 // Login はメールアドレスとパスワードを検証し、成功すればトークンペアを返します。
-func (s *AuthService) Login(ctx context.Context, email, password string) (*token.TokenPair, error) {
+// 二要素認証が有効な場合はトークンペアを発行せず、短命の二要素認証待ちトークンを返します。
+func (s *AuthService) Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := s.VerifyPassword(user.PasswordHash, password); err != nil {
-		return nil, errors.New("invalid password")
+		return nil, ErrInvalidCredentials
 	}
 
-	return s.jwtManager.GenerateTokenPair(user.ID, user.Username)
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+
+	if user.TOTPEnabled {
+		return newMFAChallenge(s.jwtManager, user)
+	}
+
+	tokenPair, err := s.tokenService.IssueTokens(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveLogin(metrics.LoginPassword, "")
+	return &LoginResult{User: user, TokenPair: tokenPair}, nil
 }
 
-// RegisterUser は新しいユーザーを登録します。
+// newMFAChallenge はトークンペアの代わりに二要素認証待ちトークンを発行したログインの結果を返します。
+// パスワードログインとOAuthログインのどちらも、二要素認証が有効なユーザーはCompleteMFALoginでコードを検証します。
+func newMFAChallenge(jwtManager *token.JWTManager, user *models.User) (*LoginResult, error) {
+	mfaToken, err := jwtManager.GenerateMFAToken(user.ID, mfaTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{
+		User:         user,
+		MFARequired:  true,
+		MFAToken:     mfaToken,
+		MFAExpiresIn: int64(mfaTokenDuration.Seconds()),
+	}, nil
+}
+
+// CompleteMFALogin は二要素認証待ちトークンとTOTPコード（またはリカバリーコード）を検証し、トークンペアを発行します。
+// 二要素認証待ちトークンは有効期間内なら何度でも使用できるため、コードの誤りはユーザーごとに数えてロックします（TwoFactorService.VerifyCode）。
+func (s *AuthService) CompleteMFALogin(ctx context.Context, mfaToken, code string, client ClientInfo) (*LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CompleteMFALogin")
+	defer span.End()
+
+	userID, err := s.jwtManager.VerifyMFAToken(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+
+	if err := s.twoFactorService.VerifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.tokenService.IssueTokens(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveLogin(metrics.LoginMFA, "")
+	return &LoginResult{User: user, TokenPair: tokenPair}, nil
+}
+
+// RegisterUser は新しいユーザーを未確認状態で登録し、メールアドレスの確認メールを送信します。
+// 確認メールの送信に失敗しても登録は成功とし、ユーザーは後から再送できます。
 func (s *AuthService) RegisterUser(ctx context.Context, user *models.User) error {
-	return s.userRepo.Create(ctx, user)
+	ctx, span := tracing.Start(ctx, "AuthService.RegisterUser")
+	defer span.End()
+
+	user.EmailVerified = false
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.emailVerifyService.SendVerification(ctx, user); err != nil {
+		logging.FromContext(ctx).Error("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+	return nil
 }
 
-// RefreshToken はリフレッシュトークンを使用して新しいトークンペアを生成します。
-func (s *AuthService) RefreshToken(refreshToken string) (*token.TokenPair, error) {
-	return s.jwtManager.RefreshTokenPair(refreshToken)
+// RefreshToken はリフレッシュトークンをローテーションし、新しいトークンペアを生成します。
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (*token.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RefreshToken")
+	defer span.End()
+
+	return s.tokenService.Refresh(ctx, refreshToken, client)
+}
+
+// Logout はリフレッシュトークンが属するセッションを失効させます。
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	return s.tokenService.Revoke(ctx, refreshToken)
 }
 
 // ValidateToken はアクセストークンを検証します。
@@ -74,9 +205,15 @@ func (s *AuthService) IsTokenExpired(tokenStr string) bool {
 
 // GetUserByID ユーザーIDでユーザー情報を取得
 func (s *AuthService) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetUserByID")
+	defer span.End()
+
 	return s.userRepo.GetByID(ctx, id)
 }
 
 func (s *AuthService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetUserByEmail")
+	defer span.End()
+
 	return s.userRepo.GetByEmail(ctx, email)
 }
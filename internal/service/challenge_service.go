@@ -4,35 +4,63 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/achievement"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/metrics"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotChallengeOwner は問題の所有者でも管理者でもないユーザーが操作しようとした場合のエラーです。
+	ErrNotChallengeOwner = apperror.Forbidden("not_challenge_owner", "user is not the owner of the challenge")
+	// ErrCategoryNotFound は指定したカテゴリが存在しない場合のエラーです。
+	ErrCategoryNotFound = apperror.Validation("category_not_found", "category not found")
+	// ErrChallengeNotFound は問題が存在しない（公開されていない）場合のエラーです。
+	ErrChallengeNotFound = apperror.NotFound("challenge_not_found", "challenge not found")
 )
 
 type ChallengeService interface {
 	CreateChallenge(ctx context.Context, challenge *models.Challenge, categoryName string) error
-	CollectByUsername(ctx context.Context, username string) ([]*models.Challenge, error)
-	UpdateChallenge(ctx context.Context, challengeID uint, userID uint, req *dtos.UpdateChallengeRequest) error
+	CollectPublicByUsername(ctx context.Context, username string, viewerID uint) ([]*dtos.ChallengePublicDTO, error)
+	CollectByUserID(ctx context.Context, userID uint) ([]*models.Challenge, error)
+	UpdateChallenge(ctx context.Context, challengeID uint, userID uint, role string, req *dtos.UpdateChallengeRequest) error
 	DeleteChallenge(ctx context.Context, challengeID uint, userID uint) error
-	GetChallengeByID(ctx context.Context, challengeID uint, userID uint) (*dtos.ChallengeDetailResponse, error)
+	GetChallengeByID(ctx context.Context, challengeID uint, userID uint, role string) (*dtos.ChallengeDetailResponse, error)
 	GetPublicChallengeByID(ctx context.Context, challengeID uint, userID uint) (*dtos.ChallengePublicDTO, error)
 	GetAllPublicChallenges(ctx context.Context, userID uint) ([]*dtos.ChallengePublicDTO, error)
-	SubmitFlag(ctx context.Context, challengeID uint, userID uint, flag string) (bool, error)
+	SubmitFlag(ctx context.Context, challengeID uint, userID uint, flag string) (*dtos.SubmissionResponse, error)
 }
 
 type challengeService struct {
 	challengerepo repository.ChallengeRepository
 	userrepo      repository.UserRepository
+	achievements  *AchievementService
 }
 
 // 以前の修正コード
-func NewChallengeService(challengerepo repository.ChallengeRepository, userrepo repository.UserRepository) ChallengeService {
-	return &challengeService{challengerepo: challengerepo, userrepo: userrepo}
+func NewChallengeService(challengerepo repository.ChallengeRepository, userrepo repository.UserRepository, achievements *AchievementService) ChallengeService {
+	return &challengeService{challengerepo: challengerepo, userrepo: userrepo, achievements: achievements}
 }
 
 // CreateChallengeは、カテゴリー名を解決して新しい問題をデータベースに保存します。
+// 公開状態で作成するには、メールアドレスが確認済みである必要があります。
 func (s *challengeService) CreateChallenge(ctx context.Context, challenge *models.Challenge, categoryName string) error {
+	ctx, span := tracing.Start(ctx, "ChallengeService.CreateChallenge")
+	defer span.End()
+
+	if challenge.IsPublic {
+		if err := s.requireVerifiedEmail(ctx, challenge.UserID); err != nil {
+			return err
+		}
+	}
+
 	// カテゴリー名が提供されている場合、IDを検索します
 	if categoryName != "" {
 		category, err := s.challengerepo.FindCategoryByName(ctx, categoryName)
@@ -40,7 +68,7 @@ func (s *challengeService) CreateChallenge(ctx context.Context, challenge *model
 			return fmt.Errorf("failed to find category: %w", err)
 		}
 		if category == nil {
-			return fmt.Errorf("category '%s' not found", categoryName)
+			return ErrCategoryNotFound.WithParam("category", categoryName)
 		}
 		challenge.CategoryID = &category.ID
 	} else {
@@ -49,25 +77,77 @@ func (s *challengeService) CreateChallenge(ctx context.Context, challenge *model
 	}
 
 	// サービスはリポジトリのCreateメソッドを呼び出してデータベース操作を行います
-	return s.challengerepo.Create(ctx, challenge)
+	if err := s.challengerepo.Create(ctx, challenge); err != nil {
+		return err
+	}
+
+	if challenge.IsPublic {
+		s.evaluateAchievements(ctx, challenge.UserID, achievement.EventPublish, challenge.ID)
+	}
+	return nil
 }
 
-func (s *challengeService) CollectByUsername(ctx context.Context, username string) ([]*models.Challenge, error) {
-	userID, err := s.userrepo.GetIDByUsername(ctx, username)
+// CollectPublicByUsernameは、ユーザー名で指定したユーザーが作成した公開中の問題を取得します。
+// IsSolvedは閲覧しているユーザー（viewerID）が解いたかどうかです。
+func (s *challengeService) CollectPublicByUsername(ctx context.Context, username string, viewerID uint) ([]*dtos.ChallengePublicDTO, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.CollectPublicByUsername")
+	defer span.End()
+
+	user, err := s.userrepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsBanned() {
+		return nil, ErrUserNotFound
+	}
+
+	challenges, err := s.challengerepo.CollectPublicByUserID(ctx, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user ID: %w", err)
+		return nil, err
 	}
+	return s.toPublicDTOs(ctx, challenges, viewerID)
+}
+
+// CollectByUserIDは、ユーザーIDで指定したユーザーが作成した問題を取得します。
+func (s *challengeService) CollectByUserID(ctx context.Context, userID uint) ([]*models.Challenge, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.CollectByUserID")
+	defer span.End()
+
 	return s.challengerepo.CollectByUserID(ctx, userID)
 }
 
-func (s *challengeService) UpdateChallenge(ctx context.Context, challengeID uint, userID uint, req *dtos.UpdateChallengeRequest) error {
-	challenge, err := s.challengerepo.GetByID(ctx, challengeID)
+// canManage は問題の所有者または管理者であるかを判定します。
+func canManage(challenge *models.Challenge, userID uint, role string) bool {
+	return challenge.UserID == userID || role == models.RoleAdmin
+}
+
+// requireVerifiedEmail はユーザーのメールアドレスが確認済みであることを確認します。
+func (s *challengeService) requireVerifiedEmail(ctx context.Context, userID uint) error {
+	user, err := s.userrepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
 
-	if challenge.UserID != userID {
-		return errors.New("user is not the owner of the challenge")
+// UpdateChallengeは問題を更新します。管理者は他のユーザーの問題も更新・非公開化できます。
+func (s *challengeService) UpdateChallenge(ctx context.Context, challengeID uint, userID uint, role string, req *dtos.UpdateChallengeRequest) error {
+	ctx, span := tracing.Start(ctx, "ChallengeService.UpdateChallenge")
+	defer span.End()
+
+	challenge, err := s.challengerepo.GetByID(ctx, challengeID)
+	if err != nil {
+		return challengeNotFound(err)
+	}
+
+	if !canManage(challenge, userID, role) {
+		return ErrNotChallengeOwner
 	}
 
 	if req.Title != nil {
@@ -82,7 +162,15 @@ func (s *challengeService) UpdateChallenge(ctx context.Context, challengeID uint
 	if req.Flag != nil {
 		challenge.Flag = *req.Flag
 	}
+	published := false
 	if req.IsPublic != nil {
+		// 非公開から公開に切り替える場合は、操作するユーザーのメールアドレスが確認済みである必要があります
+		if *req.IsPublic && !challenge.IsPublic {
+			published = true
+			if err := s.requireVerifiedEmail(ctx, userID); err != nil {
+				return err
+			}
+		}
 		challenge.IsPublic = *req.IsPublic
 	}
 
@@ -92,37 +180,54 @@ func (s *challengeService) UpdateChallenge(ctx context.Context, challengeID uint
 			return fmt.Errorf("failed to find category: %w", err)
 		}
 		if category == nil {
-			return fmt.Errorf("category '%s' not found", *req.Category)
+			return ErrCategoryNotFound.WithParam("category", *req.Category)
 		}
 		challenge.CategoryID = &category.ID
+		challenge.Category = category
 	} else {
+		// GetByIDでPreloadしたCategoryが残っているとSaveでCategoryIDが戻るため、両方をクリアする
 		challenge.CategoryID = nil
+		challenge.Category = nil
+	}
+
+	if err := s.challengerepo.Update(ctx, challenge); err != nil {
+		return err
 	}
 
-	return s.challengerepo.Update(ctx, challenge)
+	// 実績は管理者が公開した場合も問題の作成者に対して判定します
+	if published {
+		s.evaluateAchievements(ctx, challenge.UserID, achievement.EventPublish, challenge.ID)
+	}
+	return nil
 }
 
 func (s *challengeService) DeleteChallenge(ctx context.Context, challengeID uint, userID uint) error {
+	ctx, span := tracing.Start(ctx, "ChallengeService.DeleteChallenge")
+	defer span.End()
+
 	challenge, err := s.challengerepo.GetByID(ctx, challengeID)
 	if err != nil {
-		return err
+		return challengeNotFound(err)
 	}
 
 	if challenge.UserID != userID {
-		return errors.New("user is not the owner of the challenge")
+		return ErrNotChallengeOwner
 	}
 
 	return s.challengerepo.Delete(ctx, challengeID)
 }
 
-func (s *challengeService) GetChallengeByID(ctx context.Context, challengeID uint, userID uint) (*dtos.ChallengeDetailResponse, error) {
+func (s *challengeService) GetChallengeByID(ctx context.Context, challengeID uint, userID uint, role string) (*dtos.ChallengeDetailResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.GetChallengeByID")
+	defer span.End()
+
 	challenge, err := s.challengerepo.GetByID(ctx, challengeID)
 	if err != nil {
-		return nil, err
+		return nil, challengeNotFound(err)
 	}
 
-	if challenge.UserID != userID {
-		return nil, errors.New("user is not the owner of the challenge")
+	if !canManage(challenge, userID, role) {
+		return nil, ErrNotChallengeOwner
 	}
 
 	var categoryName *string
@@ -142,9 +247,12 @@ func (s *challengeService) GetChallengeByID(ctx context.Context, challengeID uin
 }
 
 func (s *challengeService) GetPublicChallengeByID(ctx context.Context, challengeID uint, userID uint) (*dtos.ChallengePublicDTO, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.GetPublicChallengeByID")
+	defer span.End()
+
 	challenge, err := s.challengerepo.GetPublicByID(ctx, challengeID)
 	if err != nil {
-		return nil, err
+		return nil, challengeNotFound(err)
 	}
 
 	isSolved, err := s.challengerepo.IsSolved(ctx, challengeID, userID)
@@ -156,18 +264,25 @@ func (s *challengeService) GetPublicChallengeByID(ctx context.Context, challenge
 		ID:          challenge.ID,
 		Title:       challenge.Title,
 		Description: challenge.Description,
-		Category:    challenge.Category.Name,
+		Category:    categoryName(challenge),
 		Score:       challenge.Score,
 		IsSolved:    isSolved,
 	}, nil
 }
 
 func (s *challengeService) GetAllPublicChallenges(ctx context.Context, userID uint) ([]*dtos.ChallengePublicDTO, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.GetAllPublicChallenges")
+	defer span.End()
+
 	challenges, err := s.challengerepo.GetAllPublic(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return s.toPublicDTOs(ctx, challenges, userID)
+}
 
+// toPublicDTOsは、問題を公開用のDTOに変換します。IsSolvedはuserIDのユーザーが解いたかどうかです。
+func (s *challengeService) toPublicDTOs(ctx context.Context, challenges []*models.Challenge, userID uint) ([]*dtos.ChallengePublicDTO, error) {
 	publicChallenges := make([]*dtos.ChallengePublicDTO, len(challenges))
 	for i, challenge := range challenges {
 		isSolved, err := s.challengerepo.IsSolved(ctx, challenge.ID, userID)
@@ -175,16 +290,11 @@ func (s *challengeService) GetAllPublicChallenges(ctx context.Context, userID ui
 			return nil, err
 		}
 
-		categoryName := ""
-		if challenge.Category != nil {
-			categoryName = challenge.Category.Name
-		}
-
 		publicChallenges[i] = &dtos.ChallengePublicDTO{
 			ID:          challenge.ID,
 			Title:       challenge.Title,
 			Description: challenge.Description,
-			Category:    categoryName,
+			Category:    categoryName(challenge),
 			Score:       challenge.Score,
 			IsSolved:    isSolved,
 		}
@@ -193,10 +303,35 @@ func (s *challengeService) GetAllPublicChallenges(ctx context.Context, userID ui
 	return publicChallenges, nil
 }
 
-func (s *challengeService) SubmitFlag(ctx context.Context, challengeID uint, userID uint, flag string) (bool, error) {
+// categoryNameは、問題のカテゴリー名を返します。カテゴリー未設定の場合は空文字列です。
+func categoryName(challenge *models.Challenge) string {
+	if challenge.Category == nil {
+		return ""
+	}
+	return challenge.Category.Name
+}
+
+func (s *challengeService) SubmitFlag(ctx context.Context, challengeID uint, userID uint, flag string) (*dtos.SubmissionResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChallengeService.SubmitFlag")
+	defer span.End()
+
+	user, err := s.userrepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
 	challenge, err := s.challengerepo.GetByID(ctx, challengeID)
 	if err != nil {
-		return false, err
+		return nil, challengeNotFound(err)
 	}
 
 	correct := challenge.Flag == flag
@@ -206,11 +341,36 @@ func (s *challengeService) SubmitFlag(ctx context.Context, challengeID uint, use
 		ChallengeID: challengeID,
 		Flag:        flag,
 		IsCorrect:   correct,
+		SubmittedAt: time.Now(),
 	}
 
 	if err := s.challengerepo.CreateSubmission(ctx, submission); err != nil {
-		return false, err
+		return nil, err
 	}
+	metrics.ObserveSubmission(correct)
 
-	return correct, nil
+	res := &dtos.SubmissionResponse{Correct: correct}
+	if correct {
+		res.UnlockedAchievements = s.evaluateAchievements(ctx, userID, achievement.EventSolve, challengeID)
+	}
+	return res, nil
+}
+
+// evaluateAchievementsは、イベントの後に実績を判定して新しく解除した実績を返します。
+// 実績の判定に失敗しても提出や公開自体は成功しているため、エラーはログに記録するだけにします。
+func (s *challengeService) evaluateAchievements(ctx context.Context, userID uint, event achievement.Event, challengeID uint) []*dtos.AchievementDTO {
+	unlocked, err := s.achievements.Evaluate(ctx, userID, event, challengeID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to evaluate achievements", "user_id", userID, "event", string(event), "error", err)
+		return nil
+	}
+	return unlocked
+}
+
+// challengeNotFound は問題の取得で見つからなかったエラーをErrChallengeNotFoundに変換します。
+func challengeNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrChallengeNotFound
+	}
+	return err
 }
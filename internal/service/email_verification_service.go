@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/mailer"
+)
+
+// 確認リンク用トークンの用途を示すaudienceです。他の署名付きトークンとの取り違えを防ぎます。
+const (
+	emailVerificationPurpose = "email_verification"
+	emailChangePurpose       = "email_change"
+)
+
+var (
+	ErrEmailNotVerified         = apperror.Forbidden("email_not_verified", "email address is not verified")
+	ErrEmailAlreadyVerified     = apperror.Conflict("email_already_verified", "email address is already verified")
+	ErrInvalidVerificationToken = apperror.Validation("invalid_verification_token", "invalid or expired verification token")
+	ErrEmailInUse               = apperror.Conflict("email_in_use", "email address is already in use")
+	// ErrEmailNotSet はメールアドレスのないユーザー（OAuthで取得できなかった場合）に確認メールを送ろうとした場合のエラーです。
+	ErrEmailNotSet = apperror.Conflict("email_not_set", "no email address is set")
+	// ErrVerificationThrottled は確認メールの再送の間隔が短すぎる場合のエラーです。RetryAfterに待ち時間を設定して返します。
+	ErrVerificationThrottled = apperror.RateLimited("verification_throttled", "verification email was sent recently, please retry later")
+)
+
+// emailVerificationClaims は確認リンクに埋め込む署名付きトークンのクレームです。
+// メールアドレスを含めることで、発行後にアドレスが変更された場合はリンクが無効になります。
+type emailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// EmailVerificationService はメールアドレス確認のビジネスロジックを提供します。
+type EmailVerificationService struct {
+	userRepo       repository.UserRepository
+	mailer         mailer.Mailer
+	secret         []byte
+	verifyURL      string
+	changeURL      string
+	ttl            time.Duration
+	resendCooldown time.Duration
+}
+
+// NewEmailVerificationService はEmailVerificationServiceのコンストラクタです。
+// verifyURLとchangeURLはメールに記載するリンクのベースURLで、クエリパラメータtokenが付与されます。
+func NewEmailVerificationService(
+	userRepo repository.UserRepository,
+	mailer mailer.Mailer,
+	secret string,
+	verifyURL string,
+	changeURL string,
+	ttl time.Duration,
+	resendCooldown time.Duration,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:       userRepo,
+		mailer:         mailer,
+		secret:         []byte(secret),
+		verifyURL:      verifyURL,
+		changeURL:      changeURL,
+		ttl:            ttl,
+		resendCooldown: resendCooldown,
+	}
+}
+
+// SendVerification は確認リンクを含むメールを送信します。
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	ctx, span := tracing.Start(ctx, "EmailVerificationService.SendVerification")
+	defer span.End()
+
+	now := time.Now()
+	signed, err := s.signToken(emailVerificationPurpose, user.ID, user.Email, now)
+	if err != nil {
+		return err
+	}
+
+	link := s.verifyURL + "?token=" + url.QueryEscape(signed)
+	locale := i18n.FromContext(ctx)
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "mail.verification.subject", nil),
+		Body: i18n.T(locale, "mail.verification.body", map[string]string{
+			"username": user.Username,
+			"link":     link,
+			"hours":    strconv.Itoa(int(s.ttl.Hours())),
+		}),
+	}); err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdateVerificationSentAt(ctx, user.ID, now)
+}
+
+// ResendVerification は確認メールを再送します。前回の送信から一定時間内は再送できません。
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "EmailVerificationService.ResendVerification")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+
+	if user.VerificationSentAt != nil {
+		if wait := s.resendCooldown - time.Since(*user.VerificationSentAt); wait > 0 {
+			return ErrVerificationThrottled.WithRetryAfter(wait)
+		}
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// Verify は確認リンクのトークンを検証し、メールアドレスを確認済みにします。
+func (s *EmailVerificationService) Verify(ctx context.Context, tokenStr string) error {
+	ctx, span := tracing.Start(ctx, "EmailVerificationService.Verify")
+	defer span.End()
+
+	user, email, err := s.parseToken(ctx, emailVerificationPurpose, tokenStr)
+	if err != nil {
+		return err
+	}
+	if user.Email != email {
+		return ErrInvalidVerificationToken
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, user.ID, email)
+}
+
+// RequestEmailChange は新しいメールアドレスを確認待ちとして保存し、新しいアドレスに確認リンクを送信します。
+// 確認が完了するまでログインや通知には現在のメールアドレスを使用します。前回の送信から一定時間内は送信できません。
+func (s *EmailVerificationService) RequestEmailChange(ctx context.Context, user *models.User, newEmail string) error {
+	ctx, span := tracing.Start(ctx, "EmailVerificationService.RequestEmailChange")
+	defer span.End()
+
+	if user.VerificationSentAt != nil {
+		if wait := s.resendCooldown - time.Since(*user.VerificationSentAt); wait > 0 {
+			return ErrVerificationThrottled.WithRetryAfter(wait)
+		}
+	}
+
+	now := time.Now()
+	signed, err := s.signToken(emailChangePurpose, user.ID, newEmail, now)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.SetPendingEmail(ctx, user.ID, newEmail); err != nil {
+		return err
+	}
+
+	link := s.changeURL + "?token=" + url.QueryEscape(signed)
+	locale := i18n.FromContext(ctx)
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      newEmail,
+		Subject: i18n.T(locale, "mail.email_change.subject", nil),
+		Body: i18n.T(locale, "mail.email_change.body", map[string]string{
+			"username": user.Username,
+			"link":     link,
+			"hours":    strconv.Itoa(int(s.ttl.Hours())),
+		}),
+	}); err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdateVerificationSentAt(ctx, user.ID, now)
+}
+
+// ConfirmEmailChange はメールアドレス変更の確認リンクのトークンを検証し、新しいメールアドレスに変更します。
+// 変更後は以前のメールアドレスに変更の通知を送信します。
+func (s *EmailVerificationService) ConfirmEmailChange(ctx context.Context, tokenStr string) error {
+	ctx, span := tracing.Start(ctx, "EmailVerificationService.ConfirmEmailChange")
+	defer span.End()
+
+	user, newEmail, err := s.parseToken(ctx, emailChangePurpose, tokenStr)
+	if err != nil {
+		return err
+	}
+	if user.PendingEmail != newEmail {
+		return ErrInvalidVerificationToken
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrEmailInUse
+	}
+
+	if err := s.userRepo.ChangeEmail(ctx, user.ID, newEmail); err != nil {
+		return err
+	}
+	// メールアドレスなしで登録したユーザーが追加した場合は、通知する変更前のアドレスがない
+	if user.Email == "" {
+		return nil
+	}
+
+	locale := i18n.FromContext(ctx)
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "mail.email_changed.subject", nil),
+		Body: i18n.T(locale, "mail.email_changed.body", map[string]string{
+			"username": user.Username,
+			"email":    newEmail,
+		}),
+	}); err != nil {
+		logging.FromContext(ctx).Error("failed to send email change notice", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// signToken は確認リンク用の署名付きトークンを作成します。
+func (s *EmailVerificationService) signToken(purpose string, userID uint, email string, now time.Time) (string, error) {
+	claims := emailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Audience:  jwt.ClaimStrings{purpose},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// parseToken は確認リンクのトークンを検証し、対象のユーザーとトークンに含まれるメールアドレスを返します。
+func (s *EmailVerificationService) parseToken(ctx context.Context, purpose, tokenStr string) (*models.User, string, error) {
+	var claims emailVerificationClaims
+	_, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(purpose),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, "", ErrInvalidVerificationToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, "", ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, uint(userID))
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrInvalidVerificationToken
+	}
+	return user, claims.Email, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+const (
+	// oauthExchangeCodeDuration は認可コードの有効期間です。
+	oauthExchangeCodeDuration = time.Minute
+	// PKCEMethodS256 はサポートするPKCEのcode_challenge_methodです。
+	PKCEMethodS256 = "S256"
+)
+
+var (
+	ErrInvalidRedirectURI  = apperror.Validation("invalid_redirect_uri", "redirect_uri is not allowed")
+	ErrPKCERequired        = apperror.Validation("pkce_required", "code_challenge with S256 method is required for loopback redirect_uri")
+	ErrInvalidExchangeCode = apperror.Validation("invalid_exchange_code", "invalid or expired authorization code")
+)
+
+// OAuthRedirect はOAuthログイン後にフロントエンドへリダイレクトするための情報です。
+type OAuthRedirect struct {
+	RedirectURI   string
+	CodeChallenge string
+}
+
+// ValidateRedirect はリダイレクト先とPKCEのパラメーターを検証します。
+// 許可リストに完全一致するURI、またはデスクトップアプリ用のループバックアドレス（ポート任意、PKCE必須）のみ許可します。
+func (s *OAuthService) ValidateRedirect(redirectURI, codeChallenge, codeChallengeMethod string) (*OAuthRedirect, error) {
+	if codeChallenge != "" && codeChallengeMethod != PKCEMethodS256 {
+		return nil, ErrPKCERequired
+	}
+
+	for _, allowed := range s.redirectAllowlist {
+		if redirectURI == allowed {
+			return &OAuthRedirect{RedirectURI: redirectURI, CodeChallenge: codeChallenge}, nil
+		}
+	}
+
+	if isLoopbackRedirect(redirectURI) {
+		if codeChallenge == "" {
+			return nil, ErrPKCERequired
+		}
+		return &OAuthRedirect{RedirectURI: redirectURI, CodeChallenge: codeChallenge}, nil
+	}
+
+	return nil, ErrInvalidRedirectURI
+}
+
+// HandleOAuthCallbackWithCode はOAuthログインを処理し、トークンの代わりに使い捨ての認可コードを発行します。
+// フロントエンドはリダイレクト先で受け取ったコードをExchangeCodeでトークンと交換します。
+// ユーザー名の選択が必要な場合は*SignupRequiredErrorを返します。登録待ちにはPKCEのcode_challengeを引き継ぎます。
+func (s *OAuthService) HandleOAuthCallbackWithCode(ctx context.Context, identity *OAuthIdentity, redirect *OAuthRedirect) (string, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.HandleOAuthCallbackWithCode")
+	defer span.End()
+
+	user, err := s.signIn(ctx, identity, redirect.CodeChallenge)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.exchangeRepo.Create(ctx, &models.OAuthExchangeCode{
+		UserID:        user.ID,
+		Provider:      identity.Provider,
+		CodeHash:      hashToken(code),
+		RedirectURI:   redirect.RedirectURI,
+		CodeChallenge: redirect.CodeChallenge,
+		ExpiresAt:     time.Now().Add(oauthExchangeCodeDuration),
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ExchangeCode は認可コードを検証してトークンペアを発行します。コードは一度しか使用できません。
+// redirectURIは認可コードの発行時と一致する必要があり、PKCEを使用した場合はcodeVerifierも検証します。
+// 二要素認証が有効なユーザーの場合はトークンペアの代わりに二要素認証待ちトークンを返します。
+func (s *OAuthService) ExchangeCode(ctx context.Context, code, redirectURI, codeVerifier string, client ClientInfo) (*LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.ExchangeCode")
+	defer span.End()
+
+	record, err := s.exchangeRepo.Consume(ctx, hashToken(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrExchangeCodeNotFound) {
+			return nil, ErrInvalidExchangeCode
+		}
+		return nil, err
+	}
+
+	if record.RedirectURI != redirectURI {
+		return nil, ErrInvalidExchangeCode
+	}
+	if record.CodeChallenge != "" && !verifyPKCE(record.CodeChallenge, codeVerifier) {
+		return nil, ErrInvalidExchangeCode
+	}
+
+	user, err := s.userRepo.GetByID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidExchangeCode
+	}
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	return s.login(ctx, user, record.Provider, client)
+}
+
+// verifyPKCE はcode_verifierのSHA-256がcode_challengeと一致するかを検証します（RFC 7636）。
+func verifyPKCE(codeChallenge, codeVerifier string) bool {
+	if codeVerifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(codeVerifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(codeChallenge)) == 1
+}
+
+// isLoopbackRedirect はデスクトップアプリ用のループバックアドレスへのリダイレクトかどうかを判定します（RFC 8252）。
+// localhostは名前解決やファイアウォールの設定によってループバック以外に向く可能性があるため許可せず、
+// IPアドレスのリテラル（127.0.0.1と[::1]）だけを許可します（RFC 8252 8.3節）。
+func isLoopbackRedirect(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme != "http" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	host := u.Hostname()
+	return host == "127.0.0.1" || host == "::1"
+}
@@ -0,0 +1,27 @@
+package service
+
+import "testing"
+
+func TestIsLoopbackRedirect(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want bool
+	}{
+		{uri: "http://127.0.0.1:51234/callback", want: true},
+		{uri: "http://[::1]:51234/callback", want: true},
+		{uri: "http://127.0.0.1/callback", want: true},
+		// localhostは名前解決の結果がループバックとは限らない
+		{uri: "http://localhost:51234/callback", want: false},
+		{uri: "http://127.0.0.2:51234/callback", want: false},
+		{uri: "http://[::ffff:127.0.0.1]:51234/callback", want: false},
+		{uri: "https://127.0.0.1:51234/callback", want: false},
+		{uri: "http://user@127.0.0.1:51234/callback", want: false},
+		{uri: "http://127.0.0.1:51234/callback#fragment", want: false},
+		{uri: "http://example.com/callback", want: false},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackRedirect(tt.uri); got != tt.want {
+			t.Errorf("isLoopbackRedirect(%q) = %v, want %v", tt.uri, got, tt.want)
+		}
+	}
+}
@@ -3,105 +3,512 @@ package service
 import (
 	"context"
 	"errors"
+	"net/url"
 	"time"
 
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/metrics"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
 	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
 	"gorm.io/gorm"
 )
 
+const (
+	// oauthLinkTokenDuration はOAuthプロバイダー連携トークンの有効期間です。
+	oauthLinkTokenDuration = 5 * time.Minute
+	// oauthSignupTokenDuration はユーザー名選択の登録待ちトークンの有効期間です。
+	oauthSignupTokenDuration = 15 * time.Minute
+)
+
+var (
+	ErrOAuthEmailInUse       = apperror.Conflict("oauth_email_in_use", "an account with this email already exists; sign in and link the provider from your account")
+	ErrOAuthIdentityInUse    = apperror.Conflict("oauth_identity_in_use", "this provider account is already linked to another user")
+	ErrProviderAlreadyLinked = apperror.Conflict("provider_already_linked", "a different account of this provider is already linked")
+	ErrOAuthAccountNotFound  = apperror.NotFound("oauth_account_not_found", "oauth account not found")
+	ErrLastLoginMethod       = apperror.Conflict("last_login_method", "cannot unlink the last login method")
+	ErrInvalidSignupToken    = apperror.Validation("invalid_signup_token", "invalid or expired signup token")
+)
+
+// SignupRequiredError は初めてのOAuthログインで、ユーザー名を選択して登録を完了する必要がある場合のエラーです。
+// CompleteSignupにTokenとユーザー名を渡すと登録が完了します。
+type SignupRequiredError struct {
+	Token             string
+	SuggestedUsername string
+	ExpiresIn         time.Duration
+}
+
+func (e *SignupRequiredError) Error() string {
+	return "username selection is required to complete signup"
+}
+
 type OAuthService struct {
-	oauthRepo  repository.OAuthAccountRepository
-	userRepo   repository.UserRepository
-	jwtManager *token.JWTManager
+	oauthRepo         repository.OAuthAccountRepository
+	userRepo          repository.UserRepository
+	exchangeRepo      repository.OAuthExchangeCodeRepository
+	signupRepo        repository.PendingOAuthSignupRepository
+	tokenService      *TokenService
+	jwtManager        *token.JWTManager
+	redirectAllowlist []string
+	chooseUsername    bool // 初めてのOAuthログインでユーザー名の選択ステップを挟むか
 }
 
-func NewOAuthService(oauthRepo repository.OAuthAccountRepository, userRepo repository.UserRepository, jwtManager *token.JWTManager) *OAuthService {
+func NewOAuthService(
+	oauthRepo repository.OAuthAccountRepository,
+	userRepo repository.UserRepository,
+	exchangeRepo repository.OAuthExchangeCodeRepository,
+	signupRepo repository.PendingOAuthSignupRepository,
+	tokenService *TokenService,
+	jwtManager *token.JWTManager,
+	redirectAllowlist []string,
+	chooseUsername bool,
+) *OAuthService {
 	return &OAuthService{
-		oauthRepo:  oauthRepo,
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
-	}
-}
-
-func (s *OAuthService) HandleOAuthCallback(
-	ctx context.Context,
-	username string,
-	provider string,
-	providerUserID string,
-	email string,
-	accessToken string,
-	refreshToken string,
-	tokenExpiry time.Time,
-) (*token.TokenPair, error) {
-	// OAuthアカウントが存在するか確認
-	account, err := s.oauthRepo.FindByProviderAndProviderUserID(provider, providerUserID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		oauthRepo:         oauthRepo,
+		userRepo:          userRepo,
+		exchangeRepo:      exchangeRepo,
+		signupRepo:        signupRepo,
+		tokenService:      tokenService,
+		jwtManager:        jwtManager,
+		redirectAllowlist: redirectAllowlist,
+		chooseUsername:    chooseUsername,
+	}
+}
+
+// OAuthIdentity はOAuthプロバイダーから取得したユーザー情報です。
+type OAuthIdentity struct {
+	Provider       string
+	ProviderUserID string
+	Name           string // 表示名（新規ユーザー作成時のユーザー名の候補に使用）
+	NickName       string
+	Email          string
+	AccessToken    string
+	RefreshToken   string
+	TokenExpiry    time.Time
+}
+
+// HandleOAuthCallback はOAuthログインを処理し、ログインの結果を返します。
+// 二要素認証が有効なユーザーの場合はパスワードログインと同じく、トークンペアの代わりに二要素認証待ちトークンを返します。
+// ユーザー名の選択が必要な場合は*SignupRequiredErrorを返します。
+func (s *OAuthService) HandleOAuthCallback(ctx context.Context, identity *OAuthIdentity, client ClientInfo) (*LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.HandleOAuthCallback")
+	defer span.End()
+
+	user, err := s.signIn(ctx, identity, "")
+	if err != nil {
 		return nil, err
 	}
+	return s.login(ctx, user, identity.Provider, client)
+}
 
-	var user *models.User
+// login はOAuthで認証したユーザーにトークンペアを発行します。
+// 二要素認証が有効な場合はトークンペアを発行せず、AuthService.CompleteMFALoginで検証する二要素認証待ちトークンを返します。
+func (s *OAuthService) login(ctx context.Context, user *models.User, provider string, client ClientInfo) (*LoginResult, error) {
+	if user.TOTPEnabled {
+		return newMFAChallenge(s.jwtManager, user)
+	}
+
+	tokenPair, err := s.tokenService.IssueTokens(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveLogin(metrics.LoginOAuth, provider)
+	return &LoginResult{User: user, TokenPair: tokenPair}, nil
+}
+
+// CompleteSignup はユーザー名を確定してOAuthの新規登録を完了し、トークンペアを発行します。
+// usernameが空の場合は提案したユーザー名（使用済みの場合は数字を付加）を使用します。
+// 認証開始時にPKCEを使用した場合はcodeVerifierも検証します。
+func (s *OAuthService) CompleteSignup(ctx context.Context, signupToken, username, codeVerifier string, client ClientInfo) (*token.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.CompleteSignup")
+	defer span.End()
+
+	signup, err := s.signupRepo.GetActiveByTokenHash(ctx, hashToken(signupToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrPendingSignupNotFound) {
+			return nil, ErrInvalidSignupToken
+		}
+		return nil, err
+	}
+	if signup.CodeChallenge != "" && !verifyPKCE(signup.CodeChallenge, codeVerifier) {
+		return nil, ErrInvalidSignupToken
+	}
+
+	// 登録待ちの間に同じOAuthアカウントが登録・連携された場合
+	account, err := s.findAccount(ctx, signup.Provider, signup.ProviderUserID)
+	if err != nil {
+		return nil, err
+	}
 	if account != nil {
-		user, err = s.userRepo.GetByID(ctx, account.UserID)
+		return nil, ErrOAuthIdentityInUse
+	}
+
+	if username == "" {
+		username, err = availableUsername(ctx, s.userRepo, signup.SuggestedUsername)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		if err := ValidateUsername(username); err != nil {
+			return nil, err
+		}
+		existing, err := s.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrUsernameTaken
+		}
+	}
+
+	if err := s.signupRepo.MarkUsed(ctx, signup.ID); err != nil {
+		if errors.Is(err, repository.ErrPendingSignupNotFound) {
+			return nil, ErrInvalidSignupToken
+		}
+		return nil, err
+	}
+
+	user, err := s.createUser(ctx, username, &OAuthIdentity{
+		Provider:       signup.Provider,
+		ProviderUserID: signup.ProviderUserID,
+		Email:          signup.Email,
+		AccessToken:    signup.AccessToken,
+		RefreshToken:   signup.RefreshToken,
+		TokenExpiry:    signup.TokenExpiry,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.tokenService.IssueTokens(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveLogin(metrics.LoginOAuth, signup.Provider)
+	return tokenPair, nil
+}
 
-		// トークン更新
-		if err := s.oauthRepo.UpdateTokenInfo(account.ID, accessToken, refreshToken, tokenExpiry); err != nil {
+// signIn はOAuthログインのユーザーを特定します。
+// 連携済みのアカウントがあればそのユーザーを返し、なければ新しいユーザーを作成します。
+// ユーザー名の選択ステップが有効な場合は、ユーザーを作成せずに*SignupRequiredErrorを返します。
+// 既存ユーザーへの連携はログイン中のユーザーがLinkAccountで明示的に行う必要があり、ユーザー名やメールアドレスの一致では連携しません。
+func (s *OAuthService) signIn(ctx context.Context, identity *OAuthIdentity, codeChallenge string) (*models.User, error) {
+	// OAuthアカウントが存在するか確認
+	account, err := s.findAccount(ctx, identity.Provider, identity.ProviderUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return s.signUp(ctx, identity, codeChallenge)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	// トークン更新
+	if err := s.oauthRepo.UpdateTokenInfo(ctx, account.ID, identity.AccessToken, identity.RefreshToken, identity.TokenExpiry); err != nil {
+		return nil, err
+	}
+
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	// 管理者がパスワードの再設定を強制したユーザーは、再設定するまでOAuthでもログインできない
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	return user, nil
+}
+
+// signUp は初めてのOAuthログインを処理します。
+// ユーザー名の選択ステップが有効な場合は登録待ちを作成して*SignupRequiredErrorを返し、
+// 無効な場合は提案したユーザー名でユーザーを作成します。
+func (s *OAuthService) signUp(ctx context.Context, identity *OAuthIdentity, codeChallenge string) (*models.User, error) {
+	// 同じメールアドレスのユーザーがいる場合は乗っ取りを防ぐため自動では連携しない
+	if identity.Email != "" {
+		existing, err := s.userRepo.GetByEmail(ctx, identity.Email)
+		if err != nil {
 			return nil, err
 		}
-	} else {
-		// ユーザー作成
-		// 同じ名前のユーザー名のユーザーがいるかどうかチェック
-		exitingUser, err := s.userRepo.GetByUsername(ctx, username)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if existing != nil {
+			return nil, ErrOAuthEmailInUse
+		}
+	}
+
+	suggested := suggestUsername(identity.Name, identity.NickName, identity.Email)
+
+	if !s.chooseUsername {
+		username, err := availableUsername(ctx, s.userRepo, suggested)
+		if err != nil {
 			return nil, err
 		}
-		if exitingUser != nil {
-			// 既存のユーザーが見つかった場合、そのユーザーを使用
-			user = exitingUser
-		} else {
-			// ユーザー名が重複シニア場合、新しいユーザーを作成
-			user = &models.User{
-				Email:    email,
-				Username: username,
-			}
-			if err := s.userRepo.Create(ctx, user); err != nil {
-				return nil, err
-			}
-		}
-
-		// OAuthAccount作成
-		newAccount := &models.OAuthAccount{
-			Provider:       provider,
-			ProviderUserID: providerUserID,
-			AccessToken:    accessToken,
-			RefreshToken:   refreshToken,
-			TokenExpiry:    tokenExpiry,
-			UserID:         user.ID,
-		}
-
-		if err := s.oauthRepo.Create(newAccount); err != nil {
+		return s.createUser(ctx, username, identity)
+	}
+
+	rawToken, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.signupRepo.Create(ctx, &models.PendingOAuthSignup{
+		TokenHash:         hashToken(rawToken),
+		Provider:          identity.Provider,
+		ProviderUserID:    identity.ProviderUserID,
+		Email:             identity.Email,
+		SuggestedUsername: suggested,
+		AccessToken:       identity.AccessToken,
+		RefreshToken:      identity.RefreshToken,
+		TokenExpiry:       identity.TokenExpiry,
+		CodeChallenge:     codeChallenge,
+		ExpiresAt:         time.Now().Add(oauthSignupTokenDuration),
+	}); err != nil {
+		return nil, err
+	}
+
+	return nil, &SignupRequiredError{
+		Token:             rawToken,
+		SuggestedUsername: suggested,
+		ExpiresIn:         oauthSignupTokenDuration,
+	}
+}
+
+// createUser はOAuthログインの新しいユーザーとOAuthAccountを1つのトランザクションで作成します。
+// プロバイダーが返すメールアドレスは確認済みとして扱います。メールアドレスを取得できない場合は空のまま作成します。
+func (s *OAuthService) createUser(ctx context.Context, username string, identity *OAuthIdentity) (*models.User, error) {
+	if identity.Email != "" {
+		existing, err := s.userRepo.GetByEmail(ctx, identity.Email)
+		if err != nil {
 			return nil, err
 		}
+		if existing != nil {
+			return nil, ErrOAuthEmailInUse
+		}
 	}
 
-	// JWTトークンペアを生成
-	return s.jwtManager.GenerateTokenPair(user.ID, user.Username)
+	user := &models.User{
+		Email:         identity.Email,
+		Username:      username,
+		Role:          models.RoleUser,
+		EmailVerified: identity.Email != "",
+	}
+	if user.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.CreateWithOAuthAccount(ctx, user, newOAuthAccount(0, identity)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// OAuthLink はOAuthプロバイダー連携の開始に必要な情報です。
+type OAuthLink struct {
+	// LinkToken は連携先のユーザーを示す短命のトークンです。連携を開始したブラウザのCookieにだけ保存し、URLには含めません。
+	// URLに含めると、攻撃者が発行した認可URLを開いた別のユーザーのOAuthアカウントが攻撃者に連携されてしまうためです。
+	LinkToken    string
+	AuthorizeURL string
+	ExpiresIn    time.Duration
+}
+
+// StartLink はログイン中のユーザーにOAuthプロバイダーを連携するための連携トークンと認可URLを返します。
+// OAuthの認可フローはブラウザのリダイレクトで行われAuthorizationヘッダーを使えないため、連携トークンはCookieで引き継ぎます。
+func (s *OAuthService) StartLink(ctx context.Context, userID uint, provider string) (*OAuthLink, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.StartLink")
+	defer span.End()
+
+	linkToken, err := s.jwtManager.GenerateOAuthLinkToken(userID, oauthLinkTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthLink{
+		LinkToken:    linkToken,
+		AuthorizeURL: "/auth/" + url.PathEscape(provider) + "?link=1",
+		ExpiresIn:    oauthLinkTokenDuration,
+	}, nil
+}
+
+// VerifyLinkToken はOAuthプロバイダー連携トークンを検証し、連携先のユーザーIDを返します。
+func (s *OAuthService) VerifyLinkToken(linkToken string) (uint, error) {
+	return s.jwtManager.VerifyOAuthLinkToken(linkToken)
+}
+
+// LinkAccount はOAuthアカウントをユーザーに連携します。
+// 既に同じユーザーに連携済みの場合はトークン情報のみ更新します。
+func (s *OAuthService) LinkAccount(ctx context.Context, userID uint, identity *OAuthIdentity) error {
+	ctx, span := tracing.Start(ctx, "OAuthService.LinkAccount")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsBanned() {
+		return ErrUserBanned
+	}
+
+	account, err := s.findAccount(ctx, identity.Provider, identity.ProviderUserID)
+	if err != nil {
+		return err
+	}
+	if account != nil {
+		if account.UserID != userID {
+			return ErrOAuthIdentityInUse
+		}
+		return s.oauthRepo.UpdateTokenInfo(ctx, account.ID, identity.AccessToken, identity.RefreshToken, identity.TokenExpiry)
+	}
+
+	// 1つのプロバイダーにつき連携できるアカウントは1つ
+	accounts, err := s.oauthRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if a.Provider == identity.Provider {
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	return s.oauthRepo.Create(ctx, newOAuthAccount(userID, identity))
+}
+
+// ListIdentities はユーザーのログイン方法（パスワードの有無と連携済みのOAuthプロバイダー）を返します。
+func (s *OAuthService) ListIdentities(ctx context.Context, userID uint) (*dtos.IdentitiesResponse, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.ListIdentities")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	accounts, err := s.oauthRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make([]*dtos.IdentityDTO, len(accounts))
+	for i, a := range accounts {
+		identities[i] = &dtos.IdentityDTO{
+			ID:             a.ID,
+			Provider:       a.Provider,
+			ProviderUserID: a.ProviderUserID,
+			LinkedAt:       a.CreatedAt,
+		}
+	}
+
+	return &dtos.IdentitiesResponse{
+		HasPassword: user.PasswordHash != "",
+		Identities:  identities,
+	}, nil
+}
+
+// UnlinkAccount はOAuth連携を解除します。パスワードが未設定で他に連携がない場合は、ログインできなくなるため解除できません。
+func (s *OAuthService) UnlinkAccount(ctx context.Context, userID, accountID uint) error {
+	ctx, span := tracing.Start(ctx, "OAuthService.UnlinkAccount")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	accounts, err := s.oauthRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, a := range accounts {
+		if a.ID == accountID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrOAuthAccountNotFound
+	}
+	if user.PasswordHash == "" && len(accounts) <= 1 {
+		return ErrLastLoginMethod
+	}
+
+	if err := s.oauthRepo.DeleteByUserID(ctx, userID, accountID); err != nil {
+		if errors.Is(err, repository.ErrOAuthAccountNotFound) {
+			return ErrOAuthAccountNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func newOAuthAccount(userID uint, identity *OAuthIdentity) *models.OAuthAccount {
+	return &models.OAuthAccount{
+		Provider:       identity.Provider,
+		ProviderUserID: identity.ProviderUserID,
+		AccessToken:    identity.AccessToken,
+		RefreshToken:   identity.RefreshToken,
+		TokenExpiry:    identity.TokenExpiry,
+		UserID:         userID,
+	}
+}
+
+// findAccount はOAuthアカウントを取得します。存在しない場合はnilを返します。
+func (s *OAuthService) findAccount(ctx context.Context, provider, providerUserID string) (*models.OAuthAccount, error) {
+	account, err := s.oauthRepo.FindByProviderAndProviderUserID(ctx, provider, providerUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// RefreshToken リフレッシュトークンをローテーションして新しいトークンペアを生成
+func (s *OAuthService) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (*token.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.RefreshToken")
+	defer span.End()
+
+	return s.tokenService.Refresh(ctx, refreshToken, client)
+}
+
+// Logout リフレッシュトークンが属するセッションを失効
+func (s *OAuthService) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "OAuthService.Logout")
+	defer span.End()
+
+	return s.tokenService.Revoke(ctx, refreshToken)
 }
 
 // GetUserByOAuthAccount OAuthアカウントからユーザー情報を取得
-func (s *OAuthService) GetUserByOAuthAccount(provider, providerUserID string) (*models.User, error) {
-	account, err := s.oauthRepo.FindByProviderAndProviderUserID(provider, providerUserID)
+func (s *OAuthService) GetUserByOAuthAccount(ctx context.Context, provider, providerUserID string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "OAuthService.GetUserByOAuthAccount")
+	defer span.End()
+
+	account, err := s.oauthRepo.FindByProviderAndProviderUserID(ctx, provider, providerUserID)
 	if err != nil {
 		return nil, err
 	}
 
 	if account == nil {
-		return nil, errors.New("oauth account not found")
+		return nil, ErrOAuthAccountNotFound
 	}
 
-	return s.userRepo.GetByID(context.Background(), account.UserID)
+	return s.userRepo.GetByID(ctx, account.UserID)
 }
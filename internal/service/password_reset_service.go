@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/mailer"
+)
+
+// ErrInvalidResetToken はリセットトークンが存在しない・期限切れ・使用済みの場合のエラーです。
+var ErrInvalidResetToken = apperror.Validation("invalid_reset_token", "invalid or expired reset token")
+
+// PasswordResetService はパスワードリセットのビジネスロジックを提供します。
+type PasswordResetService struct {
+	userRepo       repository.UserRepository
+	resetRepo      repository.PasswordResetTokenRepository
+	authService    *AuthService
+	tokenService   *TokenService
+	mailer         mailer.Mailer
+	resetURL       string
+	ttl            time.Duration
+	resendCooldown time.Duration
+}
+
+// NewPasswordResetService はPasswordResetServiceのコンストラクタです。
+// resetURLはメールに記載するリンクのベースURLで、クエリパラメータtokenが付与されます。
+// resendCooldownは同じユーザーにリセット用のメールを再び送信できるようになるまでの期間です（0の場合は制限なし）。
+func NewPasswordResetService(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetTokenRepository,
+	authService *AuthService,
+	tokenService *TokenService,
+	mailer mailer.Mailer,
+	resetURL string,
+	ttl time.Duration,
+	resendCooldown time.Duration,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:       userRepo,
+		resetRepo:      resetRepo,
+		authService:    authService,
+		tokenService:   tokenService,
+		mailer:         mailer,
+		resetURL:       resetURL,
+		ttl:            ttl,
+		resendCooldown: resendCooldown,
+	}
+}
+
+// RequestReset はリセット用リンクをメールで送信します。
+// メールアドレスの登録有無を推測されないよう、ユーザーが存在しない場合もエラーを返しません。
+// 同じユーザーへの大量のメール送信を防ぐため、resendCooldown以内に発行したトークンが有効なうちは送信しません（この場合もエラーを返しません）。
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "PasswordResetService.RequestReset")
+	defer span.End()
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+	if s.resendCooldown > 0 {
+		recent, err := s.resetRepo.ExistsActiveCreatedSince(ctx, user.ID, time.Now().Add(-s.resendCooldown))
+		if err != nil {
+			return err
+		}
+		if recent {
+			return nil
+		}
+	}
+
+	// 以前に発行した未使用のトークンは無効にする
+	if err := s.resetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	rawToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	if err := s.resetRepo.Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}); err != nil {
+		return err
+	}
+
+	link := s.resetURL + "?token=" + url.QueryEscape(rawToken)
+	locale := i18n.FromContext(ctx)
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "mail.password_reset.subject", nil),
+		Body: i18n.T(locale, "mail.password_reset.body", map[string]string{
+			"username": user.Username,
+			"link":     link,
+			"minutes":  strconv.Itoa(int(s.ttl.Minutes())),
+		}),
+	})
+}
+
+// ResetPassword はリセットトークンを検証してパスワードを更新します。
+// 更新後はパスワード再設定の強制を解除し、既存のセッションをすべて失効させます。
+func (s *PasswordResetService) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "PasswordResetService.ResetPassword")
+	defer span.End()
+
+	token, err := s.resetRepo.GetByTokenHash(ctx, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.resetRepo.MarkUsed(ctx, token.ID); err != nil {
+		if errors.Is(err, repository.ErrResetTokenAlreadyUsed) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashed, err := s.authService.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, token.UserID, hashed); err != nil {
+		return err
+	}
+	if err := s.userRepo.SetPasswordResetRequired(ctx, token.UserID, false); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeAllForUser(ctx, token.UserID)
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+var (
+	ErrInvalidCurrentPassword = apperror.Validation("invalid_current_password", "current password is incorrect")
+	ErrPasswordNotSet         = apperror.Validation("password_not_set", "password is not set for this account")
+	ErrEmailUnchanged         = apperror.Validation("email_unchanged", "new email address is the same as the current one")
+	ErrInvalidAvatarURL       = apperror.Validation("invalid_avatar_url", "avatar_url must be an http or https URL")
+	// ErrUsernameChangeThrottled はユーザー名を変更してから再び変更できるまでの期間内の場合のエラーです。RetryAfterに待ち時間を設定して返します。
+	ErrUsernameChangeThrottled = apperror.RateLimited("username_change_throttled", "username was changed recently, please retry later")
+)
+
+// ProfileService はログイン中のユーザー自身によるプロフィール・パスワード・メールアドレスの変更と、公開プロフィールを提供します。
+type ProfileService struct {
+	userRepo           repository.UserRepository
+	challengeRepo      repository.ChallengeRepository
+	authService        *AuthService
+	tokenService       *TokenService
+	emailVerifyService *EmailVerificationService
+	achievementService *AchievementService
+	usernameCooldown   time.Duration
+}
+
+// NewProfileService はProfileServiceのコンストラクタです。
+// usernameCooldownはユーザー名を再び変更できるようになるまでの期間です。
+func NewProfileService(
+	userRepo repository.UserRepository,
+	challengeRepo repository.ChallengeRepository,
+	authService *AuthService,
+	tokenService *TokenService,
+	emailVerifyService *EmailVerificationService,
+	achievementService *AchievementService,
+	usernameCooldown time.Duration,
+) *ProfileService {
+	return &ProfileService{
+		userRepo:           userRepo,
+		challengeRepo:      challengeRepo,
+		authService:        authService,
+		tokenService:       tokenService,
+		emailVerifyService: emailVerifyService,
+		achievementService: achievementService,
+		usernameCooldown:   usernameCooldown,
+	}
+}
+
+// UpdateProfile はプロフィールを更新し、更新後のユーザーを返します。
+// ユーザー名は使用済みの場合は変更できず、前回の変更から一定期間内は再変更できません。
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID uint, req *dtos.UpdateProfileRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "ProfileService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Username != nil && *req.Username != user.Username {
+		if err := s.changeUsername(ctx, user, *req.Username); err != nil {
+			return nil, err
+		}
+	}
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Bio != nil {
+		user.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*req.AvatarURL)
+		if avatarURL != "" && !isHTTPURL(avatarURL) {
+			return nil, ErrInvalidAvatarURL
+		}
+		user.AvatarURL = avatarURL
+	}
+	if req.HideSolves != nil {
+		user.HideSolves = *req.HideSolves
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUsernameTaken) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword は現在のパスワードを検証してパスワードを変更します。
+// 変更後は現在のセッション以外のセッションをすべて失効させます。
+// パスワードが未設定のアカウント（OAuthのみで登録）はパスワードリセットで設定する必要があります。
+func (s *ProfileService) ChangePassword(ctx context.Context, userID uint, currentSessionID, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "ProfileService.ChangePassword")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash == "" {
+		return ErrPasswordNotSet
+	}
+	if err := s.authService.VerifyPassword(user.PasswordHash, currentPassword); err != nil {
+		return ErrInvalidCurrentPassword
+	}
+
+	hashed, err := s.authService.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashed); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeOtherSessions(ctx, user.ID, currentSessionID)
+}
+
+// RequestEmailChange は新しいメールアドレスに確認リンクを送信します。リンクを開くまでメールアドレスは変更されません。
+// パスワードが設定されているアカウントでは現在のパスワードが必要です。
+func (s *ProfileService) RequestEmailChange(ctx context.Context, userID uint, newEmail, currentPassword string) error {
+	ctx, span := tracing.Start(ctx, "ProfileService.RequestEmailChange")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash != "" {
+		if err := s.authService.VerifyPassword(user.PasswordHash, currentPassword); err != nil {
+			return ErrInvalidCurrentPassword
+		}
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if newEmail == user.Email {
+		return ErrEmailUnchanged
+	}
+	existing, err := s.userRepo.GetByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrEmailInUse
+	}
+
+	return s.emailVerifyService.RequestEmailChange(ctx, user, newEmail)
+}
+
+// GetPublicProfile はユーザー名で指定したユーザーの公開プロフィールと成績・解除した実績を返します。
+// 存在しないユーザーとBAN中のユーザーはErrUserNotFoundを返します。
+// 解いた問題を非表示にしているユーザーの場合、本人（viewerID）以外には解いた問題とカテゴリー別の成績に加えて、
+// 解いた問題から分かる合計スコア・順位・解いた問題数と、フラグの正解で解除する実績も返しません。
+func (s *ProfileService) GetPublicProfile(ctx context.Context, username string, viewerID uint) (*dtos.PublicProfileResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProfileService.GetPublicProfile")
+	defer span.End()
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsBanned() {
+		return nil, ErrUserNotFound
+	}
+
+	hidden := user.HideSolves && user.ID != viewerID
+
+	authored, err := s.challengeRepo.CollectPublicByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	achievements, err := s.achievementService.ListUnlocked(ctx, user.ID, hidden)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dtos.PublicProfileResponse{
+		Username:           user.Username,
+		DisplayName:        user.DisplayName,
+		Bio:                user.Bio,
+		AvatarURL:          user.AvatarURL,
+		JoinedAt:           user.CreatedAt,
+		SolvesHidden:       hidden,
+		AuthoredChallenges: make([]*dtos.AuthoredChallengeDTO, len(authored)),
+		Achievements:       achievements,
+	}
+	for i, c := range authored {
+		res.AuthoredChallenges[i] = &dtos.AuthoredChallengeDTO{
+			ID:        c.ID,
+			Title:     c.Title,
+			Category:  categoryName(c),
+			Score:     c.Score,
+			CreatedAt: c.CreatedAt,
+		}
+	}
+	if hidden {
+		return res, nil
+	}
+
+	solves, err := s.challengeRepo.CollectSolvesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.SolveCount = len(solves)
+	for _, solve := range solves {
+		res.TotalScore += solve.Score
+	}
+
+	if res.TotalScore > 0 {
+		above, err := s.challengeRepo.CountUsersWithScoreAbove(ctx, res.TotalScore)
+		if err != nil {
+			return nil, err
+		}
+		rank := int(above) + 1
+		res.Rank = &rank
+	}
+
+	totals, err := s.challengeRepo.CountPublicByCategory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res.Solves = make([]*dtos.SolveDTO, len(solves))
+	res.Categories = make([]*dtos.CategoryStatDTO, len(totals))
+	stats := make(map[string]*dtos.CategoryStatDTO, len(totals))
+	for i, t := range totals {
+		res.Categories[i] = &dtos.CategoryStatDTO{Category: t.Category, Total: t.Count}
+		stats[t.Category] = res.Categories[i]
+	}
+	for i, solve := range solves {
+		res.Solves[i] = &dtos.SolveDTO{
+			ChallengeID: solve.ChallengeID,
+			Title:       solve.Title,
+			Category:    solve.Category,
+			Score:       solve.Score,
+			SolvedAt:    solve.SolvedAt,
+		}
+		if stat, ok := stats[solve.Category]; ok {
+			stat.Solved++
+			stat.Score += solve.Score
+		}
+	}
+	return res, nil
+}
+
+// changeUsername はユーザー名を検証してuserに設定します。保存はUpdateProfileで行います。
+func (s *ProfileService) changeUsername(ctx context.Context, user *models.User, username string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	if user.UsernameChangedAt != nil {
+		if wait := s.usernameCooldown - time.Since(*user.UsernameChangedAt); wait > 0 {
+			return ErrUsernameChangeThrottled.WithRetryAfter(wait)
+		}
+	}
+
+	existing, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrUsernameTaken
+	}
+
+	now := time.Now()
+	user.Username = username
+	user.UsernameChangedAt = &now
+	return nil
+}
+
+func (s *ProfileService) getUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/token"
+)
+
+var (
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	ErrRefreshTokenReused  = apperror.Unauthorized("refresh_token_reused", "refresh token reuse detected")
+	ErrSessionNotFound     = apperror.NotFound("session_not_found", "session not found")
+)
+
+// ClientInfo はトークンを発行・更新したクライアントの情報です。
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenService はトークンペアの発行と、サーバー側で保存したリフレッシュトークンのローテーション・失効を管理します。
+type TokenService struct {
+	refreshRepo repository.RefreshTokenRepository
+	userRepo    repository.UserRepository
+	jwtManager  *token.JWTManager
+}
+
+func NewTokenService(refreshRepo repository.RefreshTokenRepository, userRepo repository.UserRepository, jwtManager *token.JWTManager) *TokenService {
+	return &TokenService{
+		refreshRepo: refreshRepo,
+		userRepo:    userRepo,
+		jwtManager:  jwtManager,
+	}
+}
+
+// IssueTokens は新しいトークンファミリー（ログインセッション）を開始し、トークンペアを発行します。
+func (s *TokenService) IssueTokens(ctx context.Context, user *models.User, client ClientInfo) (*token.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.IssueTokens")
+	defer span.End()
+
+	familyID, err := token.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.jwtManager.GenerateTokenPair(user.ID, user.Username, user.Role, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.refreshRepo.Create(ctx, &models.RefreshToken{
+		UserID:     user.ID,
+		JTI:        pair.RefreshTokenID,
+		FamilyID:   familyID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		SignedInAt: now,
+		LastUsedAt: now,
+		ExpiresAt:  pair.RefreshTokenExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Refresh はリフレッシュトークンをローテーションし、新しいトークンペアを発行します。
+// 既にローテーション済みのトークンが再利用された場合は、盗用とみなしてファミリー全体を失効させます。
+// クライアント情報と最終利用日時は新しいトークンに記録されます。
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*token.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.Refresh")
+	defer span.End()
+
+	record, err := s.findRecord(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.IsRevoked() {
+		return nil, s.handleReuse(ctx, record)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.IsBanned() {
+		return nil, ErrUserBanned
+	}
+	// パスワードの再設定を強制されたユーザーは、再設定するまでトークンを更新できない
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+
+	pair, err := s.jwtManager.GenerateTokenPair(user.ID, user.Username, user.Role, record.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &models.RefreshToken{
+		UserID:     user.ID,
+		JTI:        pair.RefreshTokenID,
+		FamilyID:   record.FamilyID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		SignedInAt: record.SignedInAt,
+		LastUsedAt: time.Now(),
+		ExpiresAt:  pair.RefreshTokenExpiresAt,
+	}
+	if err := s.refreshRepo.Rotate(ctx, record.JTI, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyRotated) {
+			// 検証後に別のリクエストでローテーションされた場合も再利用として扱う
+			return nil, s.handleReuse(ctx, record)
+		}
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Revoke はリフレッシュトークンが属するファミリー（ログインセッション）を失効させます。
+// 既に期限切れのトークンは何もせず成功とします。
+func (s *TokenService) Revoke(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "TokenService.Revoke")
+	defer span.End()
+
+	record, err := s.findRecord(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, token.ErrExpiredToken) {
+			return nil
+		}
+		return err
+	}
+	return s.refreshRepo.RevokeFamily(ctx, record.FamilyID)
+}
+
+// RevokeAllForUser はユーザーのすべてのセッションを失効させます。
+func (s *TokenService) RevokeAllForUser(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "TokenService.RevokeAllForUser")
+	defer span.End()
+
+	return s.refreshRepo.RevokeAllByUserID(ctx, userID)
+}
+
+// ListSessions はユーザーの有効なセッション一覧を返します。currentSessionIDに一致するセッションにはCurrentが設定されます。
+func (s *TokenService) ListSessions(ctx context.Context, userID uint, currentSessionID string) ([]*dtos.SessionDTO, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.ListSessions")
+	defer span.End()
+
+	tokens, err := s.refreshRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*dtos.SessionDTO, len(tokens))
+	for i, t := range tokens {
+		sessions[i] = &dtos.SessionDTO{
+			ID:         t.FamilyID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			SignedInAt: t.SignedInAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.FamilyID == currentSessionID,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession はユーザーのセッションを1つ失効させます。
+func (s *TokenService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "TokenService.RevokeSession")
+	defer span.End()
+
+	if err := s.refreshRepo.RevokeFamilyByUserID(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RevokeOtherSessions は現在のセッション以外のユーザーのセッションをすべて失効させます。
+func (s *TokenService) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) error {
+	ctx, span := tracing.Start(ctx, "TokenService.RevokeOtherSessions")
+	defer span.End()
+
+	return s.refreshRepo.RevokeAllByUserIDExcept(ctx, userID, currentSessionID)
+}
+
+// IsSessionRevoked はセッションが失効（または期限切れ）しているかを判定します。token.SessionCheckerを実装します。
+func (s *TokenService) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.IsSessionRevoked")
+	defer span.End()
+
+	active, err := s.refreshRepo.IsFamilyActive(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return !active, nil
+}
+
+// findRecord はリフレッシュトークンの署名を検証し、対応する保存済みレコードを取得します。
+func (s *TokenService) findRecord(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	claims, err := s.jwtManager.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		if errors.Is(err, token.ErrExpiredToken) {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if claims.ID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	record, err := s.refreshRepo.GetByJTI(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.UserID != claims.UserID {
+		return nil, ErrInvalidRefreshToken
+	}
+	return record, nil
+}
+
+func (s *TokenService) handleReuse(ctx context.Context, record *models.RefreshToken) error {
+	logging.FromContext(ctx).Warn("refresh token reuse detected", "user_id", record.UserID, "family_id", record.FamilyID)
+	if err := s.refreshRepo.RevokeFamily(ctx, record.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/handler/dtos"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/models"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+const (
+	totpPeriod        = 30 // 秒
+	totpSkew          = 1  // 前後に許容するタイムステップ数
+	recoveryCodeCount = 10
+
+	// コードをtotpMaxFailedAttempts回続けて誤るとtotpLockoutDurationの間は検証しない（総当たりの防止）
+	totpMaxFailedAttempts = 5
+	totpLockoutDuration   = 15 * time.Minute
+)
+
+var (
+	ErrTOTPAlreadyEnabled = apperror.Conflict("totp_already_enabled", "two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = apperror.Conflict("totp_not_enabled", "two-factor authentication is not enabled")
+	ErrTOTPNotEnrolled    = apperror.Conflict("totp_not_enrolled", "two-factor authentication enrollment has not been started")
+	ErrInvalidTOTPCode    = apperror.Validation("invalid_totp_code", "invalid two-factor authentication code")
+	ErrTOTPLocked         = apperror.RateLimited("totp_locked", "too many invalid two-factor authentication codes, please retry later")
+)
+
+// TwoFactorService はTOTPによる二要素認証のビジネスロジックを提供します。
+type TwoFactorService struct {
+	userRepo     repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+	issuer       string
+}
+
+func NewTwoFactorService(userRepo repository.UserRepository, recoveryRepo repository.RecoveryCodeRepository, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		issuer:       issuer,
+	}
+}
+
+// Enroll は新しいシークレットを生成して登録中の状態にし、認証アプリ用のotpauth URIを返します。
+// Confirmで正しいコードが確認されるまで二要素認証は有効になりません。
+func (s *TwoFactorService) Enroll(ctx context.Context, userID uint) (*dtos.TOTPEnrollResponse, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Enroll")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetTOTPSecret(ctx, user.ID, key.Secret()); err != nil {
+		return nil, err
+	}
+
+	return &dtos.TOTPEnrollResponse{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+	}, nil
+}
+
+// Confirm は登録中のシークレットに対するコードを検証して二要素認証を有効にし、リカバリーコードを発行します。
+func (s *TwoFactorService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Confirm")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.EnableTOTP(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes は現在のコードを確認したうえで、リカバリーコードを再発行します（以前のコードは無効になります）。
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	if err := s.limitAttempts(ctx, user, func() error { return s.verifyTOTP(ctx, user, code) }); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// Disable はTOTPコードまたはリカバリーコードを確認したうえで、二要素認証を無効にします。
+func (s *TwoFactorService) Disable(ctx context.Context, userID uint, code string) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DisableTOTP(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteByUserID(ctx, user.ID)
+}
+
+// VerifyCode はログイン時などにTOTPコードまたはリカバリーコードを検証します。
+// リカバリーコードは一度だけ使用できます。続けて誤った場合は一定時間ErrTOTPLockedを返します。
+func (s *TwoFactorService) VerifyCode(ctx context.Context, user *models.User, code string) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.VerifyCode")
+	defer span.End()
+
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	return s.limitAttempts(ctx, user, func() error { return s.verifyCode(ctx, user, code) })
+}
+
+// limitAttempts はロック中でなければverifyでコードを検証し、誤った回数を記録します。
+// 誤りがtotpMaxFailedAttempts回に達した場合はロックしてErrTOTPLockedを返し、正しいコードで回数をリセットします。
+func (s *TwoFactorService) limitAttempts(ctx context.Context, user *models.User, verify func() error) error {
+	now := time.Now()
+	if user.TOTPLockedUntil != nil && now.Before(*user.TOTPLockedUntil) {
+		return ErrTOTPLocked.WithRetryAfter(user.TOTPLockedUntil.Sub(now))
+	}
+
+	err := verify()
+	switch {
+	case errors.Is(err, ErrInvalidTOTPCode):
+		locked, recordErr := s.userRepo.RecordTOTPFailure(ctx, user.ID, totpMaxFailedAttempts, now.Add(totpLockoutDuration))
+		if recordErr != nil {
+			return recordErr
+		}
+		if locked {
+			return ErrTOTPLocked.WithRetryAfter(totpLockoutDuration)
+		}
+		return err
+	case err != nil:
+		return err
+	}
+
+	if user.TOTPFailedAttempts > 0 || user.TOTPLockedUntil != nil {
+		return s.userRepo.ResetTOTPFailures(ctx, user.ID)
+	}
+	return nil
+}
+
+// verifyCode はTOTPコードまたはリカバリーコードを検証します。
+func (s *TwoFactorService) verifyCode(ctx context.Context, user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	if err := s.recoveryRepo.Use(ctx, user.ID, hashToken(normalizeRecoveryCode(code))); err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return ErrInvalidTOTPCode
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyTOTP はTOTPコードを検証します。同じタイムステップのコードは一度しか使用できません。
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	step, ok := matchTOTPStep(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return ErrInvalidTOTPCode
+	}
+
+	advanced, err := s.userRepo.AdvanceTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// issueRecoveryCodes は新しいリカバリーコードを生成し、ハッシュを保存して平文のコードを返します。
+func (s *TwoFactorService) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(b)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashToken(raw)
+	}
+
+	if err := s.recoveryRepo.ReplaceAll(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *TwoFactorService) getUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// matchTOTPStep は許容範囲内のタイムステップでコードが一致するかを調べ、一致したステップを返します。
+func matchTOTPStep(secret, code string, now time.Time) (int64, bool) {
+	if secret == "" || !isTOTPCode(code) {
+		return 0, false
+	}
+
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	current := now.Unix() / totpPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// isTOTPCode は6桁の数字かどうかを判定します。
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRecoveryCode は入力されたリカバリーコードから区切り文字を除き、小文字にそろえます。
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
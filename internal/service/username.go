@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/repository"
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+const (
+	UsernameMinLength = 3
+	UsernameMaxLength = 20
+	// fallbackUsername は名前からユーザー名を作れない場合に使用するユーザー名です。
+	fallbackUsername = "user"
+	// usernameSequentialTries は連番で空きを探す回数です。超えた場合はランダムな数字を付加します。
+	usernameSequentialTries = 20
+)
+
+var (
+	ErrInvalidUsername = apperror.Validation("invalid_username",
+		fmt.Sprintf("username must be %d-%d characters of letters, digits, '_' or '-'", UsernameMinLength, UsernameMaxLength)).
+		WithParam("min", strconv.Itoa(UsernameMinLength)).
+		WithParam("max", strconv.Itoa(UsernameMaxLength))
+	ErrUsernameTaken = apperror.Conflict("username_taken", "username is already taken")
+)
+
+// ValidateUsername はユーザーが指定したユーザー名の形式を検証します。
+func ValidateUsername(username string) error {
+	if len(username) < UsernameMinLength || len(username) > UsernameMaxLength {
+		return ErrInvalidUsername
+	}
+	for _, r := range username {
+		if !isUsernameRune(r) {
+			return ErrInvalidUsername
+		}
+	}
+	return nil
+}
+
+// SlugifyUsername は表示名などからユーザー名に使える文字列を作ります。
+// アクセント記号を除いてASCIIにし、小文字化して、使用できない文字は '_' に置き換えます。
+// 条件を満たすユーザー名を作れない場合は空文字列を返します。
+func SlugifyUsername(name string) string {
+	var b strings.Builder
+	pendingSep := false
+	for _, r := range norm.NFKD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // 結合文字（アクセント記号）は除く
+		}
+		r = unicode.ToLower(r)
+		if r < unicode.MaxASCII && isUsernameRune(r) && r != '_' && r != '-' {
+			if pendingSep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pendingSep = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingSep = true
+	}
+
+	slug := b.String()
+	if len(slug) > UsernameMaxLength {
+		slug = strings.TrimRight(slug[:UsernameMaxLength], "_")
+	}
+	if len(slug) < UsernameMinLength {
+		return ""
+	}
+	return slug
+}
+
+// suggestUsername はOAuthプロバイダーの名前・ニックネーム・メールアドレスのローカル部の順にユーザー名の候補を作ります。
+func suggestUsername(name, nickname, email string) string {
+	localPart, _, _ := strings.Cut(email, "@")
+	for _, candidate := range []string{name, nickname, localPart} {
+		if slug := SlugifyUsername(candidate); slug != "" {
+			return slug
+		}
+	}
+	return fallbackUsername
+}
+
+// availableUsername は未使用のユーザー名を返します。
+// baseが使用済みの場合は "base2", "base3" ... のように数字を付加し、見つからなければランダムな数字を付加します。
+func availableUsername(ctx context.Context, userRepo repository.UserRepository, base string) (string, error) {
+	candidate := base
+	for i := 0; ; i++ {
+		existing, err := userRepo.GetByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+
+		var suffix string
+		if i < usernameSequentialTries {
+			suffix = fmt.Sprint(i + 2)
+		} else {
+			n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+			if err != nil {
+				return "", err
+			}
+			suffix = fmt.Sprint(n.Int64())
+		}
+		candidate = withUsernameSuffix(base, suffix)
+	}
+}
+
+// withUsernameSuffix は最大長を超えないようにbaseを切り詰めてsuffixを付加します。
+func withUsernameSuffix(base, suffix string) string {
+	if len(base)+len(suffix) > UsernameMaxLength {
+		base = base[:UsernameMaxLength-len(suffix)]
+	}
+	return base + suffix
+}
+
+func isUsernameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-'
+}
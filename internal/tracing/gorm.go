@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// InstrumentGORM はGORMのコールバックを登録し、クエリごとにスパンを作成します。
+// スパンの親はクエリのコンテキスト（db.WithContext で渡したもの）のスパンです。
+// SQLはプレースホルダーのまま記録し、パラメーターは記録しません。
+func InstrumentGORM(db *gorm.DB) error {
+	start := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+				return // リクエストの外（起動時の処理など）のクエリは記録しない
+			}
+			_, span := Start(ctx, "gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)),
+			)
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+	end := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		if tx.Statement.Table != "" {
+			span.SetAttributes(semconv.DBCollectionName(tx.Statement.Table))
+		}
+		span.SetAttributes(
+			semconv.DBQueryText(tx.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", tx.RowsAffected),
+		)
+		if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", start("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", end),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", start("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", end),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", start("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", end),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", start("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", end),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", start("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", end),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", start("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", end),
+	)
+}
@@ -0,0 +1,132 @@
+// Package validation はリクエストの入力の検証と、検証エラーのフィールドごとの変換を提供します。
+//
+// ハンドラーはDefaultのバリデーターでリクエストを検証し、失敗した場合はエラーをそのまま登録します。
+// エラーのレスポンスを作成する際にFieldsで {field, code, message} の一覧に変換します。
+// fieldはJSONのフィールド名（配列の要素は scopes[0] のように添字を付ける）、codeは検証のルール名
+// （required・min・max・email など）で、messageだけがリクエストの言語（日本語・英語）で変わります。
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/i18n"
+)
+
+// CodeType はJSONの値の型がフィールドの型と一致しない場合のcodeです。
+const CodeType = "type"
+
+// FieldError はフィールドごとの検証エラーです。
+type FieldError struct {
+	Field   string `json:"field" example:"username"`
+	Code    string `json:"code" example:"min"`                                                 // 検証のルール名
+	Message string `json:"message" example:"username must be at least 3 characters in length"` // リクエストの言語のメッセージ
+}
+
+var universal = ut.New(en.New(), en.New(), ja.New())
+
+// メッセージの翻訳は翻訳器（言語）ごとに1回だけ登録できるため、バリデーターは1つを共有する
+var shared = sync.OnceValue(func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	enTrans, _ := universal.GetTranslator(i18n.English)
+	jaTrans, _ := universal.GetTranslator(i18n.Japanese)
+	if err := en_translations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		panic(fmt.Sprintf("validation: failed to register en translations: %v", err))
+	}
+	if err := ja_translations.RegisterDefaultTranslations(v, jaTrans); err != nil {
+		panic(fmt.Sprintf("validation: failed to register ja translations: %v", err))
+	}
+	return v
+})
+
+// Default はJSONのフィールド名でエラーを報告し、日本語と英語のメッセージを登録したバリデーターを返します。
+// バリデーターはすべてのハンドラーで共有します（並行して使用できます）。
+func Default() *validator.Validate {
+	return shared()
+}
+
+// Fields はerrに含まれる検証エラー（validator.ValidationErrors）とJSONの型のエラーを
+// localeの言語のフィールドごとのエラーに変換します。該当するエラーがない場合はnilを返します。
+func Fields(err error, locale string) []FieldError {
+	trans, found := universal.GetTranslator(locale)
+	if !found {
+		trans, _ = universal.GetTranslator(i18n.Default)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Code:    fe.Tag(),
+				Message: fe.Translate(trans),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Code:    CodeType,
+			Message: i18n.T(locale, "validation.type", map[string]string{"field": typeErr.Field, "type": jsonTypeName(typeErr.Type)}),
+		}}
+	}
+	return nil
+}
+
+// jsonFieldName はJSONタグのフィールド名を返します。JSONに含めないフィールドは空文字列（構造体のフィールド名を使用）です。
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fieldPath は "RegisterRequest.username" のような名前空間から先頭の構造体名を除きます。
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// jsonTypeName はGoの型に対応するJSONの型の名前を返します。
+func jsonTypeName(typ reflect.Type) string {
+	if typ == nil {
+		return "value"
+	}
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return jsonTypeName(typ.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -20,15 +20,24 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/CTF-Forge/CTF-Forge-backend/config"
 	_ "github.com/CTF-Forge/CTF-Forge-backend/docs" // Swagger docs
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/logging"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/metrics"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/migrate"
 	"github.com/CTF-Forge/CTF-Forge-backend/internal/router"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/server"
+	"github.com/CTF-Forge/CTF-Forge-backend/internal/tracing"
+	"github.com/CTF-Forge/CTF-Forge-backend/migrations"
 	"github.com/CTF-Forge/CTF-Forge-backend/oauth"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm"
 )
 
 // @title CTFForge API
@@ -52,28 +61,115 @@ import (
 // @description Bearer token for authentication
 
 func main() {
-	config.InitDB()
-	db := config.GetDB()
+	// 設定を読み込むまではinfoレベルで出力する
+	logLevel := new(slog.LevelVar)
+	slog.SetDefault(logging.New(os.Stdout, logLevel))
 
-	// main.go の db := config.GetDB() の後に以下のコードを追加
-	db.Logger = logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		logger.Config{
-			SlowThreshold:             time.Second, // Slow SQL threshold
-			LogLevel:                  logger.Info, // Log level
-			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
-			Colorful:                  false,       // Disable color
-		},
-	)
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+	logLevel.Set(cfg.Log.SlogLevel())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, cfg.Profile)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+
+	db, err := config.OpenDB(cfg.Database)
+	if err != nil {
+		fatal("failed to open database", err)
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := runMigrations(ctx, db); err != nil {
+			fatal("failed to run migrations", err)
+		}
+	}
+
+	if err := oauth.Init(ctx, cfg.OAuth); err != nil {
+		fatal("failed to initialize oauth providers", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("failed to get database handle", err)
+	}
+
+	if cfg.Metrics.Enabled {
+		if err := metrics.InstrumentGORM(db); err != nil {
+			fatal("failed to instrument database", err)
+		}
+	}
+	if err := tracing.InstrumentGORM(db); err != nil {
+		fatal("failed to instrument database", err)
+	}
 
-	// これで、実行されるすべてのSQLクエリがログに出力されます
+	srv := server.New(cfg.Server, router.SetupRouter(db, cfg))
+	// 停止処理は登録した順に実行される。データベースは他の処理が使い終わった後に閉じる。
+	// メールはリクエストの処理中に同期的に送信するため、処理中のリクエストを待つ間に送信も完了する。
+	// メールのキューや問題のインスタンスを停止するワーカーを追加した場合は、databaseより前に登録すること
+	srv.OnShutdown("tracing", shutdownTracing) // 未送信のスパンを送信
+	srv.OnShutdown("database", func(context.Context) error {
+		return sqlDB.Close()
+	})
 
-	oauth.Init()
+	slog.Info("using profile", "profile", cfg.Profile)
+	metricsDone := runMetricsServer(ctx, cfg)
+	if err := srv.Run(ctx); err != nil {
+		fatal("server stopped with errors", err)
+	}
+	stop()
+	if err := <-metricsDone; err != nil {
+		slog.Error("metrics server stopped with errors", "error", err)
+	}
+	slog.Info("server stopped")
+}
 
-	router := router.SetupRouter(db)
+// fatal はエラーをログに出力して終了します。
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	log.Println("Starting server on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("failed to run server:", err)
+// runMetricsServer はmetrics.addrが設定されている場合に、/metrics だけを公開するサーバーを別のポートで起動します。
+// 返したチャネルはサーバーの停止後（起動しない場合は直ちに）結果を受け取ります。
+func runMetricsServer(ctx context.Context, cfg *config.Config) <-chan error {
+	done := make(chan error, 1)
+	if !cfg.Metrics.Enabled || cfg.Metrics.Addr == "" {
+		done <- nil
+		return done
+	}
+
+	// タイムアウトはAPIサーバーと共通にし、TLSは使用しない（内部ネットワークからの収集を想定）
+	serverCfg := cfg.Server
+	serverCfg.Addr = cfg.Metrics.Addr
+	serverCfg.TLSCertFile, serverCfg.TLSKeyFile = "", ""
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler(cfg.Metrics.BearerToken))
+	go func() {
+		done <- server.New(serverCfg, mux).Run(ctx)
+	}()
+	return done
+}
+
+// runMigrations は埋め込んだマイグレーションのうち未適用のものを適用します。
+func runMigrations(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ms, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+	applied, err := migrate.NewRunner(sqlDB, ms).Up(ctx, 0)
+	for _, m := range applied {
+		slog.Info("applied migration", "migration", m.String())
 	}
+	return err
 }
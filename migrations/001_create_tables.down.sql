@@ -0,0 +1,6 @@
+DROP TABLE submissions;
+DROP TABLE docker_challenges;
+DROP TABLE challenge_files;
+DROP TABLE challenges;
+DROP TABLE challenge_categories;
+DROP TABLE users;
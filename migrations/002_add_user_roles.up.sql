@@ -0,0 +1,4 @@
+-- usersテーブルにロールを追加
+ALTER TABLE users
+  ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+  CHECK (role IN ('user', 'author', 'moderator', 'admin'));
@@ -0,0 +1,7 @@
+-- usersテーブルにアカウント状態を追加
+ALTER TABLE users
+  ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
+  CHECK (status IN ('active', 'banned'));
+ALTER TABLE users ADD COLUMN ban_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN banned_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
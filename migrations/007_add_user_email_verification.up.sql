@@ -0,0 +1,7 @@
+-- usersテーブルにメールアドレス確認状態を追加
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN verification_sent_at TIMESTAMP;
+
+-- 既存ユーザーは確認済みとして扱う
+UPDATE users SET email_verified = TRUE, email_verified_at = CURRENT_TIMESTAMP;
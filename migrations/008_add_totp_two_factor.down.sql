@@ -0,0 +1,6 @@
+DROP TABLE recovery_codes;
+
+ALTER TABLE users DROP COLUMN totp_last_used_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_enabled;
+ALTER TABLE users DROP COLUMN totp_secret;
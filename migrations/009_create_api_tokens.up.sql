@@ -0,0 +1,15 @@
+-- api_tokensテーブル（パーソナルアクセストークン）
+CREATE TABLE api_tokens (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  token_prefix VARCHAR(32) NOT NULL,
+  token_hash TEXT UNIQUE NOT NULL,
+  scopes TEXT NOT NULL,
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  revoked_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,13 @@
+-- oauth_exchange_codesテーブル（OAuthログイン後の使い捨て認可コード）
+CREATE TABLE oauth_exchange_codes (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  code_hash TEXT UNIQUE NOT NULL,
+  redirect_uri TEXT NOT NULL,
+  code_challenge TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_oauth_exchange_codes_user_id ON oauth_exchange_codes(user_id);
@@ -0,0 +1,16 @@
+-- pending_oauth_signupsテーブル（ユーザー名の選択を待っているOAuthの新規登録）
+CREATE TABLE pending_oauth_signups (
+  id SERIAL PRIMARY KEY,
+  token_hash TEXT UNIQUE NOT NULL,
+  provider TEXT NOT NULL,
+  provider_user_id TEXT NOT NULL,
+  email TEXT NOT NULL DEFAULT '',
+  suggested_username TEXT NOT NULL,
+  access_token TEXT NOT NULL DEFAULT '',
+  refresh_token TEXT NOT NULL DEFAULT '',
+  token_expiry TIMESTAMP,
+  code_challenge TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+-- usersテーブルにプロフィールとメールアドレス変更の項目を追加
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN pending_email TEXT NOT NULL DEFAULT '';
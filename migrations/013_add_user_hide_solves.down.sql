@@ -0,0 +1,3 @@
+DROP INDEX idx_submissions_user_id_correct;
+
+ALTER TABLE users DROP COLUMN hide_solves;
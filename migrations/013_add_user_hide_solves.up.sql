@@ -0,0 +1,4 @@
+-- usersテーブルに公開プロフィールで解いた問題を非表示にする設定を追加
+ALTER TABLE users ADD COLUMN hide_solves BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_submissions_user_id_correct ON submissions(user_id) WHERE is_correct = TRUE;
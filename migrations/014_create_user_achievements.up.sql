@@ -0,0 +1,8 @@
+-- user_achievementsテーブル（ユーザーが解除した実績）
+CREATE TABLE user_achievements (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  achievement_id TEXT NOT NULL,
+  unlocked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, achievement_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE oauth_accounts;
+
+ALTER TABLE challenge_categories DROP CONSTRAINT challenge_categories_name_key;
@@ -0,0 +1,19 @@
+-- モデルとの差分を修正
+-- challenge_categories.nameの一意制約
+ALTER TABLE challenge_categories ADD CONSTRAINT challenge_categories_name_key UNIQUE (name);
+
+-- oauth_accountsテーブル（手動で作成済みの環境があるためIF NOT EXISTS）
+CREATE TABLE IF NOT EXISTS oauth_accounts (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL,
+  provider_user_id TEXT NOT NULL,
+  access_token TEXT NOT NULL DEFAULT '',
+  refresh_token TEXT NOT NULL DEFAULT '',
+  token_expiry TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_user ON oauth_accounts(provider, provider_user_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_accounts_user_id ON oauth_accounts(user_id);
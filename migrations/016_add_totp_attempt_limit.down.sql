@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN totp_locked_until;
+ALTER TABLE users DROP COLUMN totp_failed_attempts;
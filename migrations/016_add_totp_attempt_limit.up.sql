@@ -0,0 +1,3 @@
+-- usersテーブルに二要素認証のコードを誤った回数とロックの期限を追加
+ALTER TABLE users ADD COLUMN totp_failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_locked_until TIMESTAMP;
@@ -0,0 +1,3 @@
+-- メールアドレスが空のユーザーが複数いる場合は失敗するため、先にメールアドレスを設定すること
+DROP INDEX idx_users_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
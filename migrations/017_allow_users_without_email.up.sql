@@ -0,0 +1,5 @@
+-- OAuthでメールアドレスを取得できないユーザーは空文字列で登録するため、メールアドレスの一意制約を空でない値に限る
+-- GORMのAutoMigrateで作成した環境の制約名（uni_users_email）も削除する
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE email <> '';
@@ -0,0 +1 @@
+ALTER TABLE oauth_exchange_codes DROP COLUMN provider;
@@ -0,0 +1,2 @@
+-- oauth_exchange_codesテーブルに認可コードを発行したOAuthプロバイダーを追加（ログインのメトリクスに使用）
+ALTER TABLE oauth_exchange_codes ADD COLUMN provider TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- 使用していないロール（author・moderator）を廃止し、ロールをuserとadminに限定
+UPDATE users SET role = 'user' WHERE role IN ('author', 'moderator');
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,10 @@
+// Package migrations はバイナリに埋め込んだSQLマイグレーションを提供します。
+//
+// ファイル名は「<バージョン>_<名前>.up.sql」と「<バージョン>_<名前>.down.sql」の組です。
+// 適用はinternal/migrateパッケージで行います。
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -1,17 +1,52 @@
 package oauth
 
 import (
+	"context"
+	"sort"
+
 	"github.com/CTF-Forge/CTF-Forge-backend/config"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/github"
 	"github.com/markbates/goth/providers/google"
 )
 
-func Init() {
-	config.InitDB()
+// Init は設定されたOAuthプロバイダーを登録します。
+// GitHub・Googleはクライアントキーが設定されている場合のみ、OIDCプロバイダーは設定に列挙したものを登録します。
+// ctxはOIDCプロバイダーのディスカバリーに使用します。
+func Init(ctx context.Context, cfg config.OAuthConfig) error {
+	var providers []goth.Provider
+	if gh := cfg.GitHub; gh.Enabled() {
+		providers = append(providers, github.New(gh.ClientID, gh.ClientSecret, gh.CallbackURL))
+	}
+	if g := cfg.Google; g.Enabled() {
+		providers = append(providers, google.New(g.ClientID, g.ClientSecret, g.CallbackURL))
+	}
+
+	for _, oidcCfg := range cfg.OIDC {
+		p, err := NewOIDCProvider(ctx, oidcCfg, nil)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, p)
+	}
+
+	goth.ClearProviders()
+	goth.UseProviders(providers...)
+	return nil
+}
+
+// IsEnabled はプロバイダーが登録済みかどうかを判定します。
+func IsEnabled(name string) bool {
+	_, err := goth.GetProvider(name)
+	return err == nil
+}
 
-	goth.UseProviders(
-		github.New(config.GetGitHubAuthKey(), config.GetGitHubAuthSecret(), config.GetGitHubCallbackURL()),
-		google.New(config.GetGoogleAuthKey(), config.GetGoogleAuthSecret(), config.GetGoogleCallbackURL()),
-	)
+// ProviderNames は登録済みのプロバイダー名を昇順で返します。
+func ProviderNames() []string {
+	names := make([]string, 0, len(goth.GetProviders()))
+	for name := range goth.GetProviders() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,159 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/openidConnect"
+)
+
+// oidcHTTPTimeout はOIDCプロバイダーへのリクエスト（ディスカバリー・JWKS・トークン・ユーザー情報）のタイムアウトです。
+// 起動時のディスカバリーで応答のない発行者を待ち続けて起動が止まらないようにします。
+const oidcHTTPTimeout = 10 * time.Second
+
+// oidcProvider は汎用OpenID Connectプロバイダーです。
+// gothのopenidConnectプロバイダーはIDトークンの署名を検証しないため、JWKSで署名を検証してからユーザー情報を取得します。
+type oidcProvider struct {
+	*openidConnect.Provider
+	verifier *oidc.IDTokenVerifier
+	client   *http.Client
+}
+
+// oidcSession はoidcProvider用のセッションです。
+// openidConnect.SessionのAuthorizeは*openidConnect.Providerを前提としているため、埋め込んだプロバイダーを渡します。
+type oidcSession struct {
+	*openidConnect.Session
+}
+
+// NewOIDCProvider は設定からOIDCプロバイダーを作成します。
+// IssuerURLのディスカバリーエンドポイントからエンドポイントとJWKSのURLを取得します。
+// clientがnilの場合はoidcHTTPTimeoutのタイムアウトを設定したクライアントを使用します。
+// テストではローカルのスタブサーバーを指すIssuerURLとそのクライアントを渡せます。
+// ディスカバリーはclientのタイムアウトにかかわらずoidcHTTPTimeoutで打ち切ります。
+func NewOIDCProvider(ctx context.Context, cfg config.OIDCProviderConfig, client *http.Client) (goth.Provider, error) {
+	if cfg.Name == "" || cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("oidc provider %q: name, issuer and client id are required", cfg.Name)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: oidcHTTPTimeout}
+	}
+
+	discoveryCtx, cancel := context.WithTimeout(oidc.ClientContext(ctx, client), oidcHTTPTimeout)
+	defer cancel()
+	discovered, err := oidc.NewProvider(discoveryCtx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc provider %q: discovery failed: %w", cfg.Name, err)
+	}
+
+	var metadata struct {
+		UserInfoURL        string `json:"userinfo_endpoint"`
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := discovered.Claims(&metadata); err != nil {
+		return nil, fmt.Errorf("oidc provider %q: invalid discovery document: %w", cfg.Name, err)
+	}
+
+	endpoint := discovered.Endpoint()
+	p, err := openidConnect.NewCustomisedURL(
+		cfg.ClientID,
+		cfg.ClientSecret,
+		cfg.CallbackURL,
+		endpoint.AuthURL,
+		endpoint.TokenURL,
+		cfg.IssuerURL,
+		metadata.UserInfoURL,
+		metadata.EndSessionEndpoint,
+		withOpenIDScope(cfg.Scopes)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.SetName(cfg.Name)
+	p.HTTPClient = client
+
+	if cfg.UserIDClaim != "" {
+		p.UserIdClaims = []string{cfg.UserIDClaim}
+	}
+	if cfg.UsernameClaim != "" {
+		p.NickNameClaims = []string{cfg.UsernameClaim}
+	}
+	if cfg.NameClaim != "" {
+		p.NameClaims = []string{cfg.NameClaim}
+	}
+	if cfg.EmailClaim != "" {
+		p.EmailClaims = []string{cfg.EmailClaim}
+	}
+
+	return &oidcProvider{
+		Provider: p,
+		verifier: discovered.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		client:   client,
+	}, nil
+}
+
+func (p *oidcProvider) BeginAuth(state string) (goth.Session, error) {
+	sess, err := p.Provider.BeginAuth(state)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcSession{Session: sess.(*openidConnect.Session)}, nil
+}
+
+func (p *oidcProvider) UnmarshalSession(data string) (goth.Session, error) {
+	sess, err := p.Provider.UnmarshalSession(data)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcSession{Session: sess.(*openidConnect.Session)}, nil
+}
+
+// FetchUser はIDトークンの署名・発行者・audience・有効期限を検証してからユーザー情報を返します。
+// email_verifiedがfalseのメールアドレスは確認済みとして扱えないため返しません。
+func (p *oidcProvider) FetchUser(session goth.Session) (goth.User, error) {
+	sess, ok := session.(*oidcSession)
+	if !ok {
+		return goth.User{}, errors.New("oidc: unexpected session type")
+	}
+	if sess.IDToken == "" {
+		return goth.User{}, fmt.Errorf("%s cannot get user information without id_token", p.Name())
+	}
+
+	// gothのProvider.FetchUserはコンテキストを受け取らないため、リクエストのコンテキストは引き継げない
+	ctx := oidc.ClientContext(context.Background(), p.client)
+	if _, err := p.verifier.Verify(ctx, sess.IDToken); err != nil {
+		return goth.User{}, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+
+	user, err := p.Provider.FetchUser(sess.Session)
+	if err != nil {
+		return user, err
+	}
+	if verified, ok := user.RawData[openidConnect.EmailVerifiedClaim].(bool); ok && !verified {
+		user.Email = ""
+	}
+	return user, nil
+}
+
+func (s *oidcSession) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p, ok := provider.(*oidcProvider)
+	if !ok {
+		return "", errors.New("oidc: unexpected provider type")
+	}
+	return s.Session.Authorize(p.Provider, params)
+}
+
+// withOpenIDScope はスコープにopenidが含まれていなければ追加します。
+func withOpenIDScope(scopes []string) []string {
+	for _, s := range scopes {
+		if s == oidc.ScopeOpenID {
+			return scopes
+		}
+	}
+	return append([]string{oidc.ScopeOpenID}, scopes...)
+}
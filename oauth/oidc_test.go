@@ -0,0 +1,259 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/markbates/goth/providers/openidConnect"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/config"
+)
+
+const (
+	testClientID = "ctfforge"
+	testKeyID    = "test-key"
+)
+
+// stubIssuer はディスカバリーとJWKSだけを返すOIDCプロバイダーのスタブです。
+type stubIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                s.server.URL,
+			"authorization_endpoint":                s.server.URL + "/authorize",
+			"token_endpoint":                        s.server.URL + "/token",
+			"jwks_uri":                              s.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"kid": testKeyID,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// idToken はkeyで署名したIDトークンを返します。標準のクレームにclaimsを上書きします。
+func (s *stubIssuer) idToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	now := time.Now()
+	all := jwt.MapClaims{
+		"iss": s.server.URL,
+		"aud": testClientID,
+		"sub": "user-123",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+	tok.Header["kid"] = testKeyID
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (s *stubIssuer) provider(t *testing.T, cfg config.OIDCProviderConfig) *oidcProvider {
+	t.Helper()
+	cfg.Name = "stub"
+	cfg.IssuerURL = s.server.URL
+	cfg.ClientID = testClientID
+	p, err := NewOIDCProvider(context.Background(), cfg, s.server.Client())
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	return p.(*oidcProvider)
+}
+
+func session(idToken string) *oidcSession {
+	return &oidcSession{Session: &openidConnect.Session{
+		AccessToken: "access-token",
+		ExpiresAt:   time.Now().Add(time.Hour),
+		IDToken:     idToken,
+	}}
+}
+
+func TestOIDCProviderFetchUser(t *testing.T) {
+	issuer := newStubIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.OIDCProviderConfig
+		key       *rsa.PrivateKey
+		claims    jwt.MapClaims
+		wantErr   bool
+		wantID    string
+		wantName  string
+		wantNick  string
+		wantEmail string
+	}{
+		{
+			name: "standard claims",
+			claims: jwt.MapClaims{
+				"name":               "Jose Garcia",
+				"preferred_username": "jose",
+				"email":              "jose@example.com",
+				"email_verified":     true,
+			},
+			wantID:    "user-123",
+			wantName:  "Jose Garcia",
+			wantNick:  "jose",
+			wantEmail: "jose@example.com",
+		},
+		{
+			name: "configured claims",
+			cfg: config.OIDCProviderConfig{
+				UserIDClaim:   "oid",
+				UsernameClaim: "login",
+				NameClaim:     "display_name",
+				EmailClaim:    "mail",
+			},
+			claims: jwt.MapClaims{
+				"oid":          "object-456",
+				"login":        "jgarcia",
+				"display_name": "J. Garcia",
+				"mail":         "jgarcia@example.com",
+				"email":        "ignored@example.com",
+			},
+			wantID:    "object-456",
+			wantName:  "J. Garcia",
+			wantNick:  "jgarcia",
+			wantEmail: "jgarcia@example.com",
+		},
+		{
+			name: "unverified email is blanked",
+			claims: jwt.MapClaims{
+				"email":          "unverified@example.com",
+				"email_verified": false,
+			},
+			wantID: "user-123",
+		},
+		{
+			name:    "signed with unknown key",
+			key:     otherKey,
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			claims:  jwt.MapClaims{"aud": "another-client"},
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			claims:  jwt.MapClaims{"iss": "https://evil.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			claims:  jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == nil {
+				key = issuer.key
+			}
+			p := issuer.provider(t, tt.cfg)
+
+			user, err := p.FetchUser(session(issuer.idToken(t, key, tt.claims)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FetchUser succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchUser: %v", err)
+			}
+			if user.UserID != tt.wantID || user.Name != tt.wantName || user.NickName != tt.wantNick || user.Email != tt.wantEmail {
+				t.Errorf("FetchUser = {UserID:%q Name:%q NickName:%q Email:%q}, want {UserID:%q Name:%q NickName:%q Email:%q}",
+					user.UserID, user.Name, user.NickName, user.Email, tt.wantID, tt.wantName, tt.wantNick, tt.wantEmail)
+			}
+			if user.Provider != "stub" {
+				t.Errorf("Provider = %q, want %q", user.Provider, "stub")
+			}
+		})
+	}
+}
+
+func TestOIDCProviderFetchUserWithoutIDToken(t *testing.T) {
+	issuer := newStubIssuer(t)
+	p := issuer.provider(t, config.OIDCProviderConfig{})
+
+	if _, err := p.FetchUser(session("")); err == nil {
+		t.Fatal("FetchUser succeeded without id_token, want error")
+	}
+}
+
+func TestNewOIDCProviderDiscoveryFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewOIDCProvider(context.Background(), config.OIDCProviderConfig{
+		Name:      "broken",
+		IssuerURL: server.URL,
+		ClientID:  testClientID,
+	}, server.Client())
+	if err == nil {
+		t.Fatal("NewOIDCProvider succeeded, want discovery error")
+	}
+}
+
+func TestNewOIDCProviderRequiresConfig(t *testing.T) {
+	tests := []config.OIDCProviderConfig{
+		{IssuerURL: "https://issuer.example.com", ClientID: testClientID},
+		{Name: "stub", ClientID: testClientID},
+		{Name: "stub", IssuerURL: "https://issuer.example.com"},
+	}
+	for _, cfg := range tests {
+		if _, err := NewOIDCProvider(context.Background(), cfg, nil); err == nil {
+			t.Errorf("NewOIDCProvider(%+v) succeeded, want error", cfg)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package apperror はAPIのクライアントに返すエラーの種類を定義します。
+//
+// サービスはドメインのエラーをこのパッケージのコンストラクタで定義し、ハンドラーはエラーを
+// gin.Context.Error で登録するだけにします。HTTPのステータスコードとレスポンス
+// （application/problem+json）への変換はハンドラー層のミドルウェアでまとめて行います。
+// クライアントに返すメッセージはCodeをキーにリクエストの言語へ翻訳します。
+// *Error 以外のエラーは内部エラーとして扱い、内容はログにだけ出力します。
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kind はエラーの種類です。HTTPのステータスコードに対応します。
+type Kind int
+
+const (
+	KindInternal     Kind = iota // 500
+	KindValidation               // 400 入力が不正
+	KindUnauthorized             // 401 認証されていない・認証情報が不正
+	KindForbidden                // 403 権限がない
+	KindNotFound                 // 404 対象が存在しない
+	KindConflict                 // 409 現在の状態と競合する（重複など）
+	KindRateLimited              // 429 回数・頻度の制限
+)
+
+// Status はKindに対応するHTTPのステータスコードを返します。
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error はクライアントに返すエラーです。
+type Error struct {
+	Kind       Kind
+	Code       string            // 機械判定用の識別子（snake_case）。同じKindとCodeのエラーはerrors.Isで一致する
+	Message    string            // 説明（英語）。ログに出力し、翻訳がない場合はクライアントに返す。内部の情報を含めない
+	Params     map[string]string // 翻訳したメッセージに埋め込む値（{name} を置き換える）
+	RetryAfter time.Duration     // KindRateLimitedの場合に再試行できるまでの時間
+	Err        error             // 原因。ログにだけ出力する
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap は原因のエラーを返します。
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is はKindとCodeが一致する場合にtrueを返します。
+// Wrapなどで作成したエラーも、元の定義（ErrXxx）とerrors.Isで比較できます。
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap は原因を付けたコピーを返します。
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithParam はメッセージに埋め込む値を追加したコピーを返します。
+func (e *Error) WithParam(name, value string) *Error {
+	c := *e
+	c.Params = make(map[string]string, len(e.Params)+1)
+	for k, v := range e.Params {
+		c.Params[k] = v
+	}
+	c.Params[name] = value
+	return &c
+}
+
+// WithRetryAfter は再試行できるまでの時間を設定したコピーを返します。
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := *e
+	c.RetryAfter = d
+	return &c
+}
+
+// New はエラーを作成します。
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Validation は入力が不正なことを表すエラーを作成します。
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// Unauthorized は認証されていないことを表すエラーを作成します。
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Forbidden は権限がないことを表すエラーを作成します。
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+// NotFound は対象が存在しないことを表すエラーを作成します。
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict は現在の状態と競合することを表すエラーを作成します。
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// RateLimited は回数・頻度の制限を超えたことを表すエラーを作成します。
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
+
+// As はerrに含まれる*Errorを返します。
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer メールを送信せずにログ出力（とファイル保存）する
+// ローカル開発とテスト用
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+// NewLogMailer 新しいLogMailerを作成
+// dirが空でない場合、メールを.emlファイルとしてdirに保存する
+func NewLogMailer(from, dir string) *LogMailer {
+	return &LogMailer{from: from, dir: dir}
+}
+
+// Send メールの宛先と件名をログに出力し、必要に応じてファイルに保存
+// 本文はトークンを含むためログに出力しない（内容はdirに保存した.emlで確認する）
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if m.dir == "" {
+		slog.InfoContext(ctx, "mail not sent (log driver)", "to", msg.To, "subject", msg.Subject)
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := filepath.Join(m.dir, fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitizeFilename(msg.To)))
+	if err := os.WriteFile(name, buildMessage(m.from, msg), 0o644); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "mail saved (log driver)", "to", msg.To, "subject", msg.Subject, "file", name)
+	return nil
+}
+
+// sanitizeFilename ファイル名に使えない文字を置き換える
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// Check 保存先のディレクトリを作成できるかを確認（保存しない場合は常に成功）
+func (m *LogMailer) Check(ctx context.Context) error {
+	if m.dir == "" {
+		return nil
+	}
+	return os.MkdirAll(m.dir, 0o755)
+}
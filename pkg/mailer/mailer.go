@@ -0,0 +1,42 @@
+// Package mailer はメール送信の抽象化と実装を提供します。
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"time"
+)
+
+// Message 送信するメール
+type Message struct {
+	To      string
+	Subject string
+	Body    string // プレーンテキスト
+}
+
+// Mailer メール送信インターフェース
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Checker 送信先に接続できるかを確認できるMailer（ヘルスチェック用）
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// buildMessage RFC 5322形式のメールを組み立てる
+func buildMessage(from string, msg *Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
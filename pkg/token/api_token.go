@@ -0,0 +1,60 @@
+package token
+
+import (
+	"context"
+	"strings"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+// APITokenPrefix パーソナルアクセストークンの接頭辞（JWTとの区別に使用）
+const APITokenPrefix = "ctfp_"
+
+// パーソナルアクセストークンのスコープ
+const (
+	ScopeProfileRead     = "profile:read"
+	ScopeChallengesRead  = "challenges:read"
+	ScopeChallengesWrite = "challenges:write"
+	ScopeSubmit          = "submit"
+)
+
+// AllScopes 発行可能なスコープの一覧
+var AllScopes = []string{
+	ScopeProfileRead,
+	ScopeChallengesRead,
+	ScopeChallengesWrite,
+	ScopeSubmit,
+}
+
+// ErrInvalidAPIToken 存在しない・失効済み・期限切れのAPIトークン
+var ErrInvalidAPIToken = apperror.Unauthorized("invalid_api_token", "invalid api token")
+
+// APITokenPrincipal APIトークンで認証されたユーザーの情報
+type APITokenPrincipal struct {
+	TokenID  uint
+	UserID   uint
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// APITokenAuthenticator パーソナルアクセストークンを検証するインターフェース
+// 無効なトークンの場合はErrInvalidAPITokenを返す
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, rawToken string) (*APITokenPrincipal, error)
+}
+
+// IsAPIToken トークン文字列がパーソナルアクセストークンかどうかを判定
+func IsAPIToken(tokenString string) bool {
+	return strings.HasPrefix(tokenString, APITokenPrefix)
+}
+
+// IsValidScope 発行可能なスコープかどうかを判定
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -1,17 +1,28 @@
 package token
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
+)
+
+// 用途を限定した短命トークンのaudience
+// アクセストークン・リフレッシュトークンはaudienceを持たないため、audienceを持つトークンは認証に使用できない
+const (
+	mfaPendingAudience = "mfa_pending" // 二要素認証待ち
+	oauthLinkAudience  = "oauth_link"  // OAuthプロバイダーの連携
 )
 
 var (
-	ErrInvalidToken     = errors.New("invalid token")
-	ErrExpiredToken     = errors.New("token has expired")
-	ErrInvalidSignature = errors.New("invalid token signature")
-	ErrInvalidClaims    = errors.New("invalid token claims")
+	ErrInvalidToken     = apperror.Unauthorized("invalid_token", "invalid token")
+	ErrExpiredToken     = apperror.Unauthorized("token_expired", "token has expired")
+	ErrInvalidSignature = apperror.Unauthorized("invalid_token_signature", "invalid token signature")
+	ErrInvalidClaims    = apperror.Unauthorized("invalid_token_claims", "invalid token claims")
 )
 
 // JWTManager JWTトークンの生成・検証を管理
@@ -25,8 +36,10 @@ type JWTManager struct {
 
 // UserClaims JWTのカスタムクレーム
 type UserClaims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"` // ログインセッションID
 	jwt.RegisteredClaims
 }
 
@@ -35,6 +48,10 @@ type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"` // 秒単位
+
+	// サーバー側でリフレッシュトークンを管理するための情報（レスポンスには含めない）
+	RefreshTokenID        string    `json:"-"` // リフレッシュトークンのjti
+	RefreshTokenExpiresAt time.Time `json:"-"`
 }
 
 // NewJWTManager 新しいJWTマネージャーを作成
@@ -49,13 +66,16 @@ func NewJWTManager(accessSecretKey, refreshSecretKey, issuer string, accessDurat
 }
 
 // GenerateTokenPair ユーザー情報からアクセストークンとリフレッシュトークンを生成
-func (j *JWTManager) GenerateTokenPair(userID uint, username string) (*TokenPair, error) {
+// sessionIDは両方のトークンのsidクレームに設定される
+func (j *JWTManager) GenerateTokenPair(userID uint, username, role, sessionID string) (*TokenPair, error) {
 	now := time.Now()
 
 	// アクセストークン生成
 	accessClaims := UserClaims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -69,14 +89,22 @@ func (j *JWTManager) GenerateTokenPair(userID uint, username string) (*TokenPair
 		return nil, err
 	}
 
-	// リフレッシュトークン生成
+	// リフレッシュトークン生成（サーバー側で追跡するためjtiを付与）
+	refreshID, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt := now.Add(j.refreshDuration)
 	refreshClaims := UserClaims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        refreshID,
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(j.refreshDuration)),
+			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
 		},
 	}
 
@@ -87,18 +115,21 @@ func (j *JWTManager) GenerateTokenPair(userID uint, username string) (*TokenPair
 	}
 
 	return &TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		ExpiresIn:    int64(j.accessDuration.Seconds()),
+		AccessToken:           accessTokenString,
+		RefreshToken:          refreshTokenString,
+		ExpiresIn:             int64(j.accessDuration.Seconds()),
+		RefreshTokenID:        refreshID,
+		RefreshTokenExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
 // GenerateAccessToken アクセストークンのみを生成
-func (j *JWTManager) GenerateAccessToken(userID uint, username string) (string, error) {
+func (j *JWTManager) GenerateAccessToken(userID uint, username, role string) (string, error) {
 	now := time.Now()
 	claims := UserClaims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -111,8 +142,16 @@ func (j *JWTManager) GenerateAccessToken(userID uint, username string) (string,
 }
 
 // VerifyAccessToken アクセストークンを検証
+// jtiを持つトークン（リフレッシュトークン）は、シークレットが同じでもアクセストークンとして使用できない
 func (j *JWTManager) VerifyAccessToken(tokenStr string) (*UserClaims, error) {
-	return j.verifyToken(tokenStr, j.accessSecretKey)
+	claims, err := j.verifyToken(tokenStr, j.accessSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ID != "" {
+		return nil, ErrInvalidClaims
+	}
+	return claims, nil
 }
 
 // VerifyRefreshToken リフレッシュトークンを検証
@@ -147,17 +186,80 @@ func (j *JWTManager) verifyToken(tokenStr, secretKey string) (*UserClaims, error
 		return nil, ErrInvalidClaims
 	}
 
+	// audienceを持つトークン（二要素認証待ち・OAuth連携・メールアドレス確認など用途限定のトークン）は
+	// 同じシークレットで署名されていても認証済みトークンとして使用できない
+	if len(claims.Audience) != 0 {
+		return nil, ErrInvalidClaims
+	}
+
 	return claims, nil
 }
 
-// RefreshTokenPair リフレッシュトークンを使用して新しいトークンペアを生成
-func (j *JWTManager) RefreshTokenPair(refreshToken string) (*TokenPair, error) {
-	claims, err := j.VerifyRefreshToken(refreshToken)
+// GenerateMFAToken パスワード認証済み・二要素認証待ちを示す短命トークンを生成
+func (j *JWTManager) GenerateMFAToken(userID uint, duration time.Duration) (string, error) {
+	return j.generatePurposeToken(mfaPendingAudience, userID, duration)
+}
+
+// VerifyMFAToken 二要素認証待ちトークンを検証し、ユーザーIDを返す
+func (j *JWTManager) VerifyMFAToken(tokenStr string) (uint, error) {
+	return j.verifyPurposeToken(tokenStr, mfaPendingAudience)
+}
+
+// GenerateOAuthLinkToken ログイン中のユーザーにOAuthプロバイダーを連携するための短命トークンを生成
+func (j *JWTManager) GenerateOAuthLinkToken(userID uint, duration time.Duration) (string, error) {
+	return j.generatePurposeToken(oauthLinkAudience, userID, duration)
+}
+
+// VerifyOAuthLinkToken OAuth連携トークンを検証し、ユーザーIDを返す
+func (j *JWTManager) VerifyOAuthLinkToken(tokenStr string) (uint, error) {
+	return j.verifyPurposeToken(tokenStr, oauthLinkAudience)
+}
+
+// generatePurposeToken 指定したaudienceを持つ用途限定トークンを生成
+func (j *JWTManager) generatePurposeToken(audience string, userID uint, duration time.Duration) (string, error) {
+	now := time.Now()
+	claims := UserClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.accessSecretKey))
+}
+
+// verifyPurposeToken 指定したaudienceを持つ用途限定トークンを検証し、ユーザーIDを返す
+func (j *JWTManager) verifyPurposeToken(tokenStr, audience string) (uint, error) {
+	claims := &UserClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenStr,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(j.accessSecretKey), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+	)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return 0, ErrExpiredToken
+		}
+		return 0, ErrInvalidToken
 	}
+	return claims.UserID, nil
+}
 
-	return j.GenerateTokenPair(claims.UserID, claims.Username)
+// NewTokenID ランダムなトークンID（jti等に使用）を生成
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // IsTokenExpired トークンが期限切れかどうかを判定
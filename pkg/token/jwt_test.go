@@ -0,0 +1,107 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testAccessSecret  = "test-access-secret"
+	testRefreshSecret = "test-refresh-secret"
+)
+
+func newTestJWTManager() *JWTManager {
+	return NewJWTManager(testAccessSecret, testRefreshSecret, "ctfforge", time.Minute, time.Hour)
+}
+
+// signed アクセストークンと同じシークレット・署名方式でclaimsに署名
+func signed(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAccessSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyAccessTokenRejectsTokensWithAudience(t *testing.T) {
+	j := newTestJWTManager()
+	now := time.Now()
+	registered := func(aud string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "1",
+			Audience:  jwt.ClaimStrings{aud},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}
+	}
+
+	mfaToken, err := j.GenerateMFAToken(1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkToken, err := j.GenerateOAuthLinkToken(1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "mfa_pending", token: mfaToken},
+		{name: "oauth_link", token: linkToken},
+		// メールアドレス確認のリンクのトークンは、シークレットが同じでもアクセストークンとして使用できない
+		{name: "email_verification", token: signed(t, struct {
+			Email string `json:"email"`
+			jwt.RegisteredClaims
+		}{Email: "user@example.com", RegisteredClaims: registered("email_verification")})},
+		{name: "email_change", token: signed(t, struct {
+			Email string `json:"email"`
+			jwt.RegisteredClaims
+		}{Email: "new@example.com", RegisteredClaims: registered("email_change")})},
+		{name: "unknown purpose", token: signed(t, &UserClaims{UserID: 1, RegisteredClaims: registered("something_else")})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := j.VerifyAccessToken(tt.token); !errors.Is(err, ErrInvalidClaims) {
+				t.Fatalf("VerifyAccessToken = %+v, %v; want %v", claims, err, ErrInvalidClaims)
+			}
+		})
+	}
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	j := newTestJWTManager()
+	pair, err := j.GenerateTokenPair(7, "alice", "user", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := j.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if claims.UserID != 7 || claims.SessionID != "session-1" || claims.Role != "user" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+// refresh_secretを省略した設定と同じく、アクセストークンと同じシークレットで署名したリフレッシュトークンを使用する
+func TestVerifyAccessTokenRejectsRefreshTokens(t *testing.T) {
+	j := NewJWTManager(testAccessSecret, testAccessSecret, "ctfforge", time.Minute, time.Hour)
+	pair, err := j.GenerateTokenPair(7, "alice", "user", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := j.VerifyRefreshToken(pair.RefreshToken); err != nil {
+		t.Fatalf("VerifyRefreshToken: %v", err)
+	}
+	if claims, err := j.VerifyAccessToken(pair.RefreshToken); !errors.Is(err, ErrInvalidClaims) {
+		t.Fatalf("VerifyAccessToken(refresh token) = %+v, %v; want %v", claims, err, ErrInvalidClaims)
+	}
+}
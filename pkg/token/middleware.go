@@ -1,28 +1,48 @@
 package token
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/CTF-Forge/CTF-Forge-backend/pkg/apperror"
 )
 
+// abort エラーを登録して処理を中断する
+// レスポンス（application/problem+json）はハンドラー層のエラーミドルウェアが返す
+func abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
 // AuthMiddleware JWT認証ミドルウェア
-func AuthMiddleware(jwtManager *JWTManager) gin.HandlerFunc {
+// sessionsがnilでない場合、失効済みセッションのアクセストークンを拒否する
+// apiTokensがnilでない場合、JWTに加えてパーソナルアクセストークンも受け付ける
+func AuthMiddleware(jwtManager *JWTManager, sessions SessionChecker, apiTokens APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authorizationヘッダーからトークンを取得
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
-			c.Abort()
+			abort(c, apperror.Unauthorized("authorization_required", "authorization header required"))
 			return
 		}
 
 		// "Bearer "プレフィックスを除去
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+			abort(c, apperror.Unauthorized("invalid_authorization_header", "invalid authorization header format"))
+			return
+		}
+
+		if apiTokens != nil && IsAPIToken(tokenString) {
+			principal, err := apiTokens.AuthenticateAPIToken(c.Request.Context(), tokenString)
+			if err != nil {
+				abort(c, err)
+				return
+			}
+
+			setAPITokenPrincipal(c, principal)
+			c.Next()
 			return
 		}
 
@@ -31,19 +51,29 @@ func AuthMiddleware(jwtManager *JWTManager) gin.HandlerFunc {
 		if err != nil {
 			switch err {
 			case ErrExpiredToken:
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "token has expired"})
+				abort(c, err)
 			case ErrInvalidToken, ErrInvalidSignature, ErrInvalidClaims:
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+				abort(c, ErrInvalidToken) // 署名とクレームのどちらが不正かは返さない
 			default:
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed"})
+				abort(c, apperror.Unauthorized("authentication_failed", "authentication failed").Wrap(err))
 			}
-			c.Abort()
+			return
+		}
+
+		revoked, err := isSessionRevoked(c, sessions, claims)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		if revoked {
+			abort(c, apperror.Unauthorized("session_revoked", "session has been revoked"))
 			return
 		}
 
 		// ユーザー情報をコンテキストに設定
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
 		c.Set("user_claims", claims)
 
 		c.Next()
@@ -51,7 +81,7 @@ func AuthMiddleware(jwtManager *JWTManager) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware オプショナルなJWT認証ミドルウェア（認証されていない場合も続行）
-func OptionalAuthMiddleware(jwtManager *JWTManager) gin.HandlerFunc {
+func OptionalAuthMiddleware(jwtManager *JWTManager, sessions SessionChecker, apiTokens APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -65,21 +95,106 @@ func OptionalAuthMiddleware(jwtManager *JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		if apiTokens != nil && IsAPIToken(tokenString) {
+			if principal, err := apiTokens.AuthenticateAPIToken(c.Request.Context(), tokenString); err == nil {
+				setAPITokenPrincipal(c, principal)
+			}
+			c.Next()
+			return
+		}
+
 		claims, err := jwtManager.VerifyAccessToken(tokenString)
 		if err != nil {
 			c.Next()
 			return
 		}
 
+		if revoked, err := isSessionRevoked(c, sessions, claims); err != nil || revoked {
+			c.Next()
+			return
+		}
+
 		// ユーザー情報をコンテキストに設定
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
 		c.Set("user_claims", claims)
 
 		c.Next()
 	}
 }
 
+// isSessionRevoked アクセストークンのセッションが失効しているかを確認
+// セッションIDを持たないトークンは確認しない
+func isSessionRevoked(c *gin.Context, sessions SessionChecker, claims *UserClaims) (bool, error) {
+	if sessions == nil || claims.SessionID == "" {
+		return false, nil
+	}
+	return sessions.IsSessionRevoked(c.Request.Context(), claims.SessionID)
+}
+
+// setAPITokenPrincipal APIトークンで認証したユーザー情報をコンテキストに設定
+func setAPITokenPrincipal(c *gin.Context, principal *APITokenPrincipal) {
+	c.Set("user_id", principal.UserID)
+	c.Set("username", principal.Username)
+	c.Set("role", principal.Role)
+	c.Set("token_scopes", principal.Scopes)
+}
+
+// RequireScope APIトークンで認証された場合に、指定したスコープを持つトークンのみ許可するミドルウェア
+// JWTで認証された場合はスコープの制限を受けない。AuthMiddlewareの後に使用する
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isAPIToken := GetScopes(c)
+		if !isAPIToken {
+			c.Next()
+			return
+		}
+
+		for _, s := range scopes {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+
+		abort(c, apperror.Forbidden("insufficient_scope", "insufficient scope").WithParam("scope", scope))
+	}
+}
+
+// DenyAPIToken APIトークンでの認証を拒否するミドルウェア
+// トークン管理やセッション管理など、ログイン済みのユーザー本人のみが行える操作に使用する
+func DenyAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIToken := GetScopes(c); isAPIToken {
+			abort(c, apperror.Forbidden("api_token_not_allowed", "api tokens are not allowed for this endpoint"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole 指定したロールのいずれかを持つユーザーのみ許可するミドルウェア
+// AuthMiddlewareの後に使用する
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := GetRole(c)
+		if !exists {
+			abort(c, apperror.Unauthorized("authentication_required", "authentication required"))
+			return
+		}
+
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		abort(c, apperror.Forbidden("insufficient_permissions", "insufficient permissions"))
+	}
+}
+
 // GetUserID コンテキストからユーザーIDを取得
 func GetUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("user_id")
@@ -106,6 +221,42 @@ func GetUsername(c *gin.Context) (string, bool) {
 	return "", false
 }
 
+// GetRole コンテキストからロールを取得
+func GetRole(c *gin.Context) (string, bool) {
+	role, exists := c.Get("role")
+	if !exists {
+		return "", false
+	}
+
+	if r, ok := role.(string); ok {
+		return r, true
+	}
+	return "", false
+}
+
+// GetSessionID コンテキストからセッションIDを取得
+func GetSessionID(c *gin.Context) (string, bool) {
+	claims, ok := GetUserClaims(c)
+	if !ok || claims.SessionID == "" {
+		return "", false
+	}
+	return claims.SessionID, true
+}
+
+// GetScopes コンテキストからAPIトークンのスコープを取得
+// APIトークンで認証されていない場合はfalseを返す
+func GetScopes(c *gin.Context) ([]string, bool) {
+	scopes, exists := c.Get("token_scopes")
+	if !exists {
+		return nil, false
+	}
+
+	if s, ok := scopes.([]string); ok {
+		return s, true
+	}
+	return nil, false
+}
+
 // GetUserClaims コンテキストからユーザークレームを取得
 func GetUserClaims(c *gin.Context) (*UserClaims, bool) {
 	claims, exists := c.Get("user_claims")
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxRevocationCacheEntries キャッシュに保持するエントリ数の上限
+// 上限に達したら期限切れのエントリを掃除し、それでも空きがなければ任意のエントリを捨てる
+const maxRevocationCacheEntries = 10000
+
+// SessionChecker セッションが失効しているかを確認するインターフェース
+type SessionChecker interface {
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RevocationCache SessionCheckerの結果を短時間キャッシュする
+// セッションを失効させてからアクセストークンが拒否されるまで、最大でTTLだけ遅れる
+type RevocationCache struct {
+	checker SessionChecker
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]revocationEntry
+}
+
+type revocationEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewRevocationCache 新しいRevocationCacheを作成
+func NewRevocationCache(checker SessionChecker, ttl time.Duration) *RevocationCache {
+	return &RevocationCache{
+		checker: checker,
+		ttl:     ttl,
+		entries: make(map[string]revocationEntry),
+	}
+}
+
+// IsSessionRevoked キャッシュを参照し、なければSessionCheckerに問い合わせる
+func (c *RevocationCache) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[sessionID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.checker.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[sessionID]; !ok && len(c.entries) >= maxRevocationCacheEntries {
+		c.evict(now)
+	}
+	c.entries[sessionID] = revocationEntry{revoked: revoked, expiresAt: now.Add(c.ttl)}
+	return revoked, nil
+}
+
+// evict 期限切れのエントリを削除し、上限未満にならなければ任意のエントリを削除する
+// 捨てたセッションは次のリクエストでSessionCheckerに問い合わせ直すだけなので、どのエントリを捨ててもよい
+// 呼び出し側でmuをロックしていること
+func (c *RevocationCache) evict(now time.Time) {
+	for id, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	for id := range c.entries {
+		if len(c.entries) < maxRevocationCacheEntries {
+			return
+		}
+		delete(c.entries, id)
+	}
+}